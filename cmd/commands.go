@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"log"
+	"os"
+	"text/tabwriter"
+	"tg-bot/internal/model"
+	"tg-bot/internal/source"
+	"tg-bot/internal/storage"
+	"time"
+)
+
+const (
+	commandsUsage = `usage: telegram-news-bot [command]
+
+Without a command the bot is started. Commands:
+  migrate up|down|status                      apply, roll back or list database migrations
+  fetch-once                                  fetch all sources once and print the saved articles
+  post-once [-dry-run]                        post the next article of every topic, or only show them
+  sources list                                list sources
+  sources add -name NAME -url URL -topic ID   add a source
+  sources import -topic ID FILE               add the feeds of an OPML file as sources of the topic
+  summarize [-type TYPE] URL                  summarize a page, for trying out prompts`
+
+	defaultSourceType = "rss"
+)
+
+var errUsage = errors.New(commandsUsage)
+
+// runCommand runs a command line subcommand instead of the bot.
+func runCommand(ctx context.Context, db *sqlx.DB, args []string) error {
+	switch args[0] {
+	case "migrate":
+		return runMigrate(ctx, db, args[1:])
+	case "fetch-once":
+		return runFetchOnce(ctx, db)
+	case "post-once":
+		return runPostOnce(ctx, db, args[1:])
+	case "sources":
+		return runSources(ctx, db, args[1:])
+	case "summarize":
+		return runSummarize(ctx, db, args[1:])
+	default:
+		return errUsage
+	}
+}
+
+func runMigrate(ctx context.Context, db *sqlx.DB, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	if args[0] == "up" {
+		return migrateUp(ctx, db)
+	}
+
+	migrator, err := storage.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "down":
+		name, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+
+		if name == "" {
+			fmt.Println("no migrations to roll back")
+		} else {
+			fmt.Printf("rolled back %s\n", name)
+		}
+
+		return nil
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := newTable("APPLIED AT", "MIGRATION")
+		for _, status := range statuses {
+			fmt.Fprintf(w, "%s\t%s\n", formatTime(status.AppliedAt, "pending"), status.Name)
+		}
+
+		return w.Flush()
+	default:
+		return errUsage
+	}
+}
+
+// migrateUp applies the pending migrations, waiting for other replicas doing the same.
+func migrateUp(ctx context.Context, db *sqlx.DB) error {
+	migrator, err := storage.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(ctx)
+	for _, name := range applied {
+		log.Printf("applied migration %s", name)
+	}
+
+	return err
+}
+
+func runFetchOnce(ctx context.Context, db *sqlx.DB) error {
+	started := time.Now()
+
+	if err := newFetcher(db).Fetch(ctx); err != nil {
+		return err
+	}
+
+	articles, err := storage.NewArticleStorage(db).ArticlesCreatedSince(ctx, started)
+	if err != nil {
+		return err
+	}
+
+	w := newTable("ID", "SOURCE", "PUBLISHED", "TITLE", "LINK")
+	for _, article := range articles {
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\n",
+			article.ID, article.SourceID, formatTime(article.PublishedAt, ""), article.Title, article.Link)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("saved %d articles\n", len(articles))
+
+	return nil
+}
+
+func runPostOnce(ctx context.Context, db *sqlx.DB, args []string) error {
+	flags := flag.NewFlagSet("post-once", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "show the articles and their summaries without sending or storing them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	aiClient, err := newAIClient()
+	if err != nil {
+		return err
+	}
+
+	if !*dryRun {
+		botAPI, err := newBotAPI()
+		if err != nil {
+			return err
+		}
+
+		return newNotifier(db, aiClient, botAPI).SelectAndSendArticle(ctx)
+	}
+
+	previews, err := newNotifier(db, aiClient, nil).Preview(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(previews) == 0 {
+		fmt.Println("nothing to post")
+	}
+
+	for _, preview := range previews {
+		fmt.Printf("Topic %d to %v\n%s\n%s\n\n", preview.TopicID, preview.Channels, preview.Article.Title,
+			preview.Article.Link)
+
+		if preview.Err != nil {
+			fmt.Printf("Failed to summarize: %v\n\n", preview.Err)
+			continue
+		}
+
+		fmt.Printf("%s\n\n", preview.Summary)
+	}
+
+	return nil
+}
+
+func runSources(ctx context.Context, db *sqlx.DB, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	sources := storage.NewSourceStorage(db)
+
+	switch args[0] {
+	case "list":
+		return listSources(ctx, sources)
+	case "add":
+		return addSource(ctx, sources, args[1:])
+	case "import":
+		return importSources(ctx, sources, args[1:])
+	default:
+		return errUsage
+	}
+}
+
+func listSources(ctx context.Context, sources *storage.SourcePostgresStorage) error {
+	list, err := sources.Sources(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := newTable("ID", "TOPIC", "PRIORITY", "LAST FETCHED", "NAME", "FEED URL", "LAST ERROR")
+	for _, s := range list {
+		fmt.Fprintf(w, "%d\t%d\t%.2f\t%s\t%s\t%s\t%s\n",
+			s.ID, s.TopicID, s.Priority, formatTime(s.LastFetchedAt, "never"), s.Name, s.FeedURL, s.LastError)
+	}
+
+	return w.Flush()
+}
+
+func addSource(ctx context.Context, sources *storage.SourcePostgresStorage, args []string) error {
+	flags := flag.NewFlagSet("sources add", flag.ContinueOnError)
+	var (
+		name       = flags.String("name", "", "name of the source")
+		feedURL    = flags.String("url", "", "feed URL")
+		topicId    = flags.Int64("topic", 0, "topic ID")
+		sourceType = flags.String("type", defaultSourceType, "source type")
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *name == "" || *feedURL == "" || *topicId == 0 {
+		return errUsage
+	}
+
+	id, err := sources.Save(ctx, model.Source{Name: *name, FeedURL: *feedURL, TopicID: *topicId, Type: *sourceType})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("added source %d\n", id)
+
+	return nil
+}
+
+// importSources adds the feeds of an OPML file to the topic, skipping the feeds that are already sources.
+func importSources(ctx context.Context, sources *storage.SourcePostgresStorage, args []string) error {
+	flags := flag.NewFlagSet("sources import", flag.ContinueOnError)
+	var (
+		topicId    = flags.Int64("topic", 0, "topic ID")
+		sourceType = flags.String("type", defaultSourceType, "source type")
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *topicId == 0 || flags.NArg() != 1 {
+		return errUsage
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	feeds, err := source.ParseOPML(file)
+	if err != nil {
+		return err
+	}
+
+	existing, err := sources.Sources(ctx)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(existing))
+	for _, s := range existing {
+		known[s.FeedURL] = true
+	}
+
+	added := 0
+
+	for _, feed := range feeds {
+		if known[feed.URL] {
+			fmt.Printf("skipped %s: already a source\n", feed.URL)
+			continue
+		}
+
+		name := feed.Title
+		if name == "" {
+			name = feed.URL
+		}
+
+		id, err := sources.Save(ctx, model.Source{Name: name, FeedURL: feed.URL, TopicID: *topicId, Type: *sourceType})
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", feed.URL, err)
+		}
+
+		known[feed.URL] = true
+		added++
+
+		fmt.Printf("added source %d: %s\n", id, name)
+	}
+
+	fmt.Printf("imported %d of %d feeds\n", added, len(feeds))
+
+	return nil
+}
+
+func runSummarize(ctx context.Context, db *sqlx.DB, args []string) error {
+	flags := flag.NewFlagSet("summarize", flag.ContinueOnError)
+	sourceType := flags.String("type", defaultSourceType, "source type choosing the prompt, e.g. translation")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+
+	aiClient, err := newAIClient()
+	if err != nil {
+		return err
+	}
+
+	summary, err := newNotifier(db, aiClient, nil).Summarize(ctx, flags.Arg(0), *sourceType)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Model: %s\nPrompt: %s\n\n%s\n", summary.Model, summary.Prompt, summary.Text)
+
+	return nil
+}
+
+func newTable(columns ...string) *tabwriter.Writer {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	for i, column := range columns {
+		if i > 0 {
+			fmt.Fprint(w, "\t")
+		}
+
+		fmt.Fprint(w, column)
+	}
+
+	fmt.Fprintln(w)
+
+	return w
+}
+
+func formatTime(t time.Time, zero string) string {
+	if t.IsZero() {
+		return zero
+	}
+
+	return t.Format(time.DateTime)
+}
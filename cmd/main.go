@@ -2,30 +2,48 @@ package main
 
 import (
 	"context"
-	"errors"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"tg-bot/internal/bot"
 	"tg-bot/internal/bot/middleware"
 	"tg-bot/internal/botkit"
+	"tg-bot/internal/cleaner"
 	"tg-bot/internal/config"
 	"tg-bot/internal/fetcher"
+	"tg-bot/internal/health"
+	"tg-bot/internal/metrics"
 	"tg-bot/internal/notifier"
+	"tg-bot/internal/ranking"
+	"tg-bot/internal/review"
+	"tg-bot/internal/server"
 	"tg-bot/internal/storage"
 	"tg-bot/internal/summary"
+	"tg-bot/internal/supervisor"
+	"time"
 )
 
+const (
+	// updateLoopMaxAge allows a few long polls of Telegram updates to fail before the bot is reported unhealthy.
+	updateLoopMaxAge = 3 * time.Minute
+	minLoopMaxAge    = 10 * time.Minute
+)
+
+// loopMaxAge is how long a loop may go without a tick: a few intervals, since one round of fetching
+// or posting may take longer than the interval.
+func loopMaxAge(interval time.Duration) time.Duration {
+	return max(3*interval, minLoopMaxAge)
+}
+
 func main() {
-	botAPI, err := tgbotapi.NewBotAPI(config.Get().TgBotToken)
-	if err != nil {
-		log.Printf("[ERROR] Failed to create a bot: %v", err)
-		return
-	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
 
 	db, err := sqlx.Connect("postgres", config.Get().DbDSN)
 	if err != nil {
@@ -39,43 +57,57 @@ func main() {
 		}
 	}(db)
 
-	var aiClient notifier.AIClient
+	if len(os.Args) > 1 {
+		if err := runCommand(ctx, db, os.Args[1:]); err != nil {
+			log.Printf("[ERROR] %v", err)
+			os.Exit(1)
+		}
 
-	if config.Get().IsLocalLLM {
-		aiClient, err = summary.NewLocalLLM()
-	} else {
-		aiClient = summary.NewOpenAIClient(config.Get().OpenAIKey)
+		return
 	}
+
+	if config.Get().MigrateOnStart {
+		if err := migrateUp(ctx, db); err != nil {
+			log.Printf("[ERROR] Failed to migrate database: %v", err)
+			return
+		}
+	}
+
+	botAPI, err := newBotAPI()
+	if err != nil {
+		log.Printf("[ERROR] Failed to create a bot: %v", err)
+		return
+	}
+
+	aiClient, err := newAIClient()
 	if err != nil {
 		log.Printf("[ERROR] Failed to create an AI client: %v", err)
 		return
 	}
 
 	var (
-		articleStorage = storage.NewArticleStorage(db)
-		sourceStorage  = storage.NewSourceStorage(db)
-		topicStorage   = storage.NewTopicStorage(db)
+		articleStorage     = storage.NewArticleStorage(db)
+		sourceStorage      = storage.NewSourceStorage(db)
+		topicStorage       = storage.NewTopicStorage(db)
+		channelStorage     = storage.NewChannelStorage(db)
+		destinationStorage = storage.NewDestinationStorage(db)
+		filterStorage      = storage.NewFilterStorage(db)
+		httpServer         = server.New(config.Get().HTTPAddr)
+
+		postFetcher = newFetcher(db)
 
-		postFetcher = fetcher.New(
+		postedRetentionDays   = retention("posted", config.Get().PostedRetentionDays)
+		unpostedRetentionDays = retention("never posted", config.Get().UnpostedRetentionDays)
+		articleCleaner        = cleaner.New(
 			articleStorage,
-			sourceStorage,
-			config.Get().FetchInterval,
-			config.Get().FilterKeywords,
+			config.Get().CleanInterval,
+			postedRetentionDays,
+			unpostedRetentionDays,
 		)
 
-		tgNotifier = notifier.NewNotifier(
-			articleStorage,
-			sourceStorage,
-			aiClient,
-			botAPI,
-			config.Get().NotificationInterval,
-			2*config.Get().FetchInterval,
-			config.Get().TgChannelId)
+		tgNotifier = newNotifier(db, aiClient, botAPI)
 	)
 
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer cancel()
-
 	newsBot := botkit.NewBot(botAPI)
 	newsBot.RegisterCmdView("start", bot.ViewCmdStart())
 	newsBot.RegisterCmdView("addsource",
@@ -104,6 +136,28 @@ func main() {
 		),
 	)
 
+	newsBot.RegisterCmdView("setpriority",
+		middleware.AdminOnly(config.Get().TgChannelId,
+			bot.ViewCmdSetPriority(sourceStorage),
+		),
+	)
+
+	newsBot.RegisterCmdView("article",
+		middleware.AdminOnly(config.Get().TgChannelId,
+			bot.ViewCmdGetArticleById(articleStorage),
+		),
+	)
+	newsBot.RegisterCmdView("unpost",
+		middleware.AdminOnly(config.Get().TgChannelId,
+			bot.ViewCmdUnpost(tgNotifier),
+		),
+	)
+	newsBot.RegisterLongCmdView("repost",
+		middleware.AdminOnly(config.Get().TgChannelId,
+			bot.ViewCmdRepost(tgNotifier),
+		),
+	)
+
 	newsBot.RegisterCmdView("topics",
 		middleware.AdminOnly(config.Get().TgChannelId,
 			bot.ViewCmdListTopics(topicStorage),
@@ -115,34 +169,227 @@ func main() {
 		),
 	)
 
-	go func(ctx context.Context) {
-		if err := postFetcher.Start(ctx); err != nil {
-			if !errors.Is(err, context.Canceled) {
-				log.Printf("[ERROR] Failed to start fetcher: %v", err)
-				return
-			}
+	newsBot.RegisterCmdView("retention",
+		middleware.AdminOnly(config.Get().TgChannelId,
+			bot.ViewCmdRetention(
+				articleStorage,
+				postedRetentionDays,
+				unpostedRetentionDays,
+			),
+		),
+	)
 
-			log.Println("fetcher stopped")
-		}
-	}(ctx)
+	newsBot.RegisterCmdView("setretention",
+		middleware.AdminOnly(config.Get().TgChannelId,
+			bot.ViewCmdSetRetention(topicStorage, cleaner.MinRetentionDays(lookupWindow())),
+		),
+	)
 
-	go func(ctx context.Context) {
-		if err := tgNotifier.Start(ctx); err != nil {
-			if !errors.Is(err, context.Canceled) {
-				log.Printf("[ERROR] failed to start notifier: %v", err)
-				return
-			}
+	newsBot.RegisterCmdView("destinations",
+		middleware.AdminOnly(config.Get().TgChannelId,
+			bot.ViewCmdListDestinations(destinationStorage),
+		),
+	)
+	newsBot.RegisterCmdView("adddestination",
+		middleware.AdminOnly(config.Get().TgChannelId,
+			bot.ViewCmdAddDestination(destinationStorage),
+		),
+	)
+	newsBot.RegisterCmdView("deletedestination",
+		middleware.AdminOnly(config.Get().TgChannelId,
+			bot.ViewCmdDeleteDestination(destinationStorage),
+		),
+	)
 
-			log.Println("notifier stopped")
-		}
-	}(ctx)
+	newsBot.RegisterCmdView("channels",
+		middleware.AdminOnly(config.Get().TgChannelId,
+			bot.ViewCmdListChannels(channelStorage),
+		),
+	)
+	newsBot.RegisterCmdView("addchannel",
+		middleware.AdminOnly(config.Get().TgChannelId,
+			middleware.AdminOf(bot.ChatFromAddChannelArgs(),
+				bot.ViewCmdAddChannel(channelStorage),
+			),
+		),
+	)
+	newsBot.RegisterCmdView("linkchannel",
+		middleware.AdminOnly(config.Get().TgChannelId,
+			middleware.AdminOf(bot.ChatFromChannelArgs(channelStorage),
+				bot.ViewCmdLinkChannel(channelStorage),
+			),
+		),
+	)
+	newsBot.RegisterCmdView("unlinkchannel",
+		middleware.AdminOnly(config.Get().TgChannelId,
+			middleware.AdminOf(bot.ChatFromChannelArgs(channelStorage),
+				bot.ViewCmdUnlinkChannel(channelStorage),
+			),
+		),
+	)
+	newsBot.RegisterCmdView("setschedule",
+		middleware.AdminOnly(config.Get().TgChannelId,
+			middleware.AdminOf(bot.ChatFromChannelArgs(channelStorage),
+				bot.ViewCmdSetSchedule(channelStorage),
+			),
+		),
+	)
+	newsBot.RegisterCmdView("setdigest",
+		middleware.AdminOnly(config.Get().TgChannelId,
+			middleware.AdminOf(bot.ChatFromChannelArgs(channelStorage),
+				bot.ViewCmdSetDigest(channelStorage),
+			),
+		),
+	)
 
-	if err := newsBot.Run(ctx); err != nil {
-		if errors.Is(err, context.Canceled) {
-			log.Printf("[ERROR] failed to start bot: %v", err)
-			return
+	if reviewChatId := config.Get().TgReviewChatId; reviewChatId != 0 {
+		newsBot.RegisterCallbackView(review.CallbackPrefix,
+			middleware.InChat(reviewChatId,
+				middleware.AdminOnly(config.Get().TgChannelId,
+					bot.ViewCallbackReview(articleStorage, tgNotifier),
+				),
+			),
+		)
+		newsBot.RegisterReplyView(
+			middleware.InChat(reviewChatId,
+				middleware.AdminOnly(config.Get().TgChannelId,
+					bot.ViewReplyEditSummary(articleStorage),
+				),
+			),
+		)
+	}
+
+	newsBot.RegisterReactionView(bot.ViewReactionFeedback(
+		articleStorage,
+		sourceStorage,
+		config.Get().ReactionPriorityStep,
+		config.Get().MinSourcePriority,
+		config.Get().MaxSourcePriority,
+	))
+
+	httpServer.HandleFeeds(articleStorage, topicStorage, channelStorage, config.Get().FeedSize)
+
+	liveness := health.NewChecker()
+	liveness.Add("telegram", health.Recent(newsBot.LastPoll, updateLoopMaxAge))
+	liveness.Add("fetcher", health.Recent(postFetcher.LastTick, loopMaxAge(config.Get().FetchInterval)))
+	liveness.Add("notifier", health.Recent(tgNotifier.LastTick, loopMaxAge(config.Get().NotificationInterval)))
+
+	readiness := health.NewChecker()
+	readiness.Add("database", db.PingContext)
+	readiness.Add("telegram", health.Recent(newsBot.LastPoll, updateLoopMaxAge))
+	readiness.Add("fetcher", health.Recent(postFetcher.LastTick, loopMaxAge(config.Get().FetchInterval)))
+	readiness.Add("notifier", health.Recent(tgNotifier.LastTick, loopMaxAge(config.Get().NotificationInterval)))
+
+	httpServer.HandleHealth(liveness, readiness)
+
+	metrics.RegisterQueue(articleStorage)
+	httpServer.Handle("GET /metrics", promhttp.Handler())
+
+	if config.Get().AdminAPIToken != "" {
+		httpServer.HandleAdminAPI(config.Get().AdminAPIToken, server.AdminAPI{
+			Sources:          sourceStorage,
+			Topics:           topicStorage,
+			Filters:          filterStorage,
+			Articles:         articleStorage,
+			MinRetentionDays: cleaner.MinRetentionDays(lookupWindow()),
+			FetchNow:         postFetcher.Trigger,
+			PostNow:          tgNotifier.Trigger,
+		})
+		httpServer.HandleDashboard(config.Get().AdminAPIToken, server.Dashboard{
+			Sources:       sourceStorage,
+			Topics:        topicStorage,
+			Articles:      articleStorage,
+			FetchInterval: config.Get().FetchInterval,
+		})
+	}
+
+	components := supervisor.New(
+		config.Get().RestartDelay,
+		config.Get().MaxRestartDelay,
+		config.Get().ShutdownTimeout,
+	)
+	components.Add("http server", func(ctx context.Context) error {
+		// A server that can't listen needs a fix of its address, not a restart.
+		if err := httpServer.Start(ctx); err != nil && ctx.Err() == nil {
+			return supervisor.Fatal(err)
 		}
 
-		log.Println("bot stopped")
+		return nil
+	})
+	components.Add("fetcher", postFetcher.Start)
+	components.Add("notifier", tgNotifier.Start)
+	components.Add("cleaner", articleCleaner.Start)
+	components.Add("bot", newsBot.Run)
+
+	if err := components.Run(ctx); err != nil {
+		log.Printf("[ERROR] Bot stopped with error: %v", err)
+		// Exit with a failure, so that the container is restarted.
+		os.Exit(1)
+	}
+}
+
+func newBotAPI() (*tgbotapi.BotAPI, error) {
+	return tgbotapi.NewBotAPIWithClient(
+		config.Get().TgBotToken,
+		tgbotapi.APIEndpoint,
+		botkit.NewRateLimitedClient(&http.Client{}),
+	)
+}
+
+func newAIClient() (notifier.AIClient, error) {
+	if config.Get().IsLocalLLM {
+		return summary.NewLocalLLM()
+	}
+
+	return summary.NewOpenAIClient(config.Get().OpenAIKey), nil
+}
+
+func newFetcher(db *sqlx.DB) *fetcher.Fetcher {
+	return fetcher.New(
+		storage.NewArticleStorage(db),
+		storage.NewSourceStorage(db),
+		storage.NewFilterStorage(db),
+		config.Get().FetchInterval,
+		config.Get().FilterKeywords,
+	)
+}
+
+// lookupWindow is how far back the notifier looks for articles to post.
+func lookupWindow() time.Duration {
+	return 2 * config.Get().FetchInterval
+}
+
+// retention returns the retention of the articles, raised to the lookup window when it is shorter so
+// that deleted articles are not fetched and posted or queued again.
+func retention(articles string, days int) int {
+	minDays := cleaner.MinRetentionDays(lookupWindow())
+	if days < minDays {
+		log.Printf("[WARN] Retention of %s articles of %d days is shorter than the lookup window of %s, keeping them for %d days",
+			articles, days, lookupWindow(), minDays)
+
+		return minDays
 	}
+
+	return days
+}
+
+// newNotifier creates the notifier. botAPI may be nil for commands that don't send anything.
+func newNotifier(db *sqlx.DB, aiClient notifier.AIClient, sender notifier.Sender) *notifier.Notifier {
+	return notifier.NewNotifier(
+		storage.NewArticleStorage(db),
+		storage.NewSourceStorage(db),
+		storage.NewChannelStorage(db),
+		storage.NewDestinationStorage(db),
+		ranking.NewScorer(
+			config.Get().RecencyHalfLife,
+			config.Get().BoostKeywords,
+			config.Get().KeywordBoost,
+			config.Get().CoverageBoost,
+		),
+		aiClient,
+		sender,
+		config.Get().NotificationInterval,
+		lookupWindow(),
+		config.Get().TgChannelId,
+		config.Get().TgReviewChatId)
 }
@@ -0,0 +1,32 @@
+package bot
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"tg-bot/internal/bot/middleware"
+	"tg-bot/internal/botkit"
+	"tg-bot/internal/model"
+)
+
+type ChannelFinder interface {
+	ChannelById(ctx context.Context, id int64) (*model.Channel, error)
+}
+
+// ChatFromChannelArgs resolves the Telegram chat of the channel referenced by "channelID" in command arguments.
+func ChatFromChannelArgs(finder ChannelFinder) middleware.ChannelResolver {
+	return func(ctx context.Context, update tgbotapi.Update) (int64, error) {
+		args, err := botkit.ParseJSON[struct {
+			ChannelID int64 `json:"channelID"`
+		}](update.Message.CommandArguments())
+		if err != nil {
+			return 0, err
+		}
+
+		channel, err := finder.ChannelById(ctx, args.ChannelID)
+		if err != nil {
+			return 0, err
+		}
+
+		return channel.ChatID, nil
+	}
+}
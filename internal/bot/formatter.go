@@ -2,17 +2,31 @@ package bot
 
 import (
 	"fmt"
+	"github.com/samber/lo"
+	"net/url"
+	"strings"
 	"tg-bot/internal/botkit/markup"
 	"tg-bot/internal/model"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	// articleTextLength caps the title, summaries and prompt shown by FormatArticle. It is halved until
+	// the message fits into telegramMessageLength.
+	articleTextLength = 1000
+	// telegramMessageLength is the longest message Telegram accepts, counted in UTF-16 code units.
+	telegramMessageLength = 4096
 )
 
 func FormatSource(source model.Source) string {
 	return fmt.Sprintf(
-		"🌐 *%s*\nID: `%d`\nFeed URL: %s\nTopic ID: `%d`",
+		"🌐 *%s*\nID: `%d`\nFeed URL: %s\nTopic ID: `%d`\nPriority: `%.2f`",
 		markup.EscapeForMarkdown(source.Name),
 		source.ID,
 		markup.EscapeForMarkdown(source.FeedURL),
 		source.TopicID,
+		source.Priority,
 	)
 }
 
@@ -24,3 +38,101 @@ func FormatTopic(topic model.Topic) string {
 		markup.EscapeForMarkdown(topic.Description),
 	)
 }
+
+func FormatRetentionReport(report model.RetentionReport) string {
+	return fmt.Sprintf(
+		"🗑 *%s*\nTopic ID: `%d`\nPosted: `%d` older than %d days\nNever posted: `%d` older than %d days",
+		markup.EscapeForMarkdown(report.TopicName),
+		report.TopicID,
+		report.ExpiredPosted,
+		report.PostedDays,
+		report.ExpiredStale,
+		report.UnpostedDays,
+	)
+}
+
+func FormatChannel(channel model.Channel) string {
+	return fmt.Sprintf(
+		"📣 *%s*\nID: `%d`\nChat ID: `%d`\nSchedule: %s\nQuiet hours: %s\nMax posts per hour: `%d`",
+		markup.EscapeForMarkdown(channel.Name),
+		channel.ID,
+		channel.ChatID,
+		markup.EscapeForMarkdown(channel.Schedule),
+		markup.EscapeForMarkdown(channel.QuietHours),
+		channel.MaxPostsPerHour,
+	)
+}
+
+// FormatDestination shows only the host of the destination URL, since webhook URLs are secrets.
+func FormatDestination(destination model.Destination) string {
+	host := destination.URL
+	if destinationURL, err := url.Parse(destination.URL); err == nil {
+		host = destinationURL.Host
+	}
+
+	return fmt.Sprintf(
+		"🔗 *%s*\nID: `%d`\nTopic ID: `%d`\nHost: %s%s%s",
+		markup.EscapeForMarkdown(destination.Type),
+		destination.ID,
+		destination.TopicID,
+		markup.EscapeForMarkdown(host),
+		markup.EscapeForMarkdown(lo.Ternary(destination.Room != "", "\nRoom: "+destination.Room, "")),
+		markup.EscapeForMarkdown(lo.Ternary(destination.Account != "", "\nAccount: "+destination.Account, "")),
+	)
+}
+
+// FormatArticle shortens the long texts of the article so that the message fits into one Telegram message.
+func FormatArticle(article model.Article, posts []model.ArticlePost) string {
+	postInfo := lo.Map(posts, func(post model.ArticlePost, _ int) string {
+		return fmt.Sprintf(
+			"Chat ID: `%d`, message ID: `%d`, %s at %s",
+			post.ChatID,
+			post.MessageID,
+			markup.EscapeForMarkdown(post.State),
+			markup.EscapeForMarkdown(post.PostedAt.Format(time.DateTime)),
+		)
+	})
+
+	for limit := articleTextLength; ; limit /= 2 {
+		text := formatArticle(article, strings.Join(postInfo, "\n"), limit)
+		if messageLength(text) <= telegramMessageLength || limit == 0 {
+			return text
+		}
+	}
+}
+
+func formatArticle(article model.Article, postInfo string, limit int) string {
+	return fmt.Sprintf(
+		"📄 *%s*\nID: `%d`\nSource ID: `%d`\nStatus: %s\nPublish state: %s%s\nLink: %s\n\n"+
+			"*Generated summary* \\(%s\\):\n%s\n\n*Edited summary*:\n%s\n\n*Prompt*:\n%s\n\n*Posts*:\n%s",
+		markup.EscapeForMarkdown(truncate(article.Title, limit)),
+		article.ID,
+		article.SourceID,
+		markup.EscapeForMarkdown(article.Status),
+		markup.EscapeForMarkdown(article.PublishState),
+		markup.EscapeForMarkdown(lo.Ternary(article.PublishError != "", " ("+truncate(article.PublishError, limit)+")", "")),
+		markup.EscapeForMarkdown(article.Link),
+		markup.EscapeForMarkdown(article.SummaryModel),
+		markup.EscapeForMarkdown(truncate(article.GeneratedSummary, limit)),
+		markup.EscapeForMarkdown(truncate(article.EditedSummary, limit)),
+		markup.EscapeForMarkdown(truncate(article.SummaryPrompt, limit)),
+		postInfo,
+	)
+}
+
+func messageLength(text string) int {
+	return len(utf16.Encode([]rune(text)))
+}
+
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+
+	if limit <= 0 {
+		return ""
+	}
+
+	return string(runes[:limit-1]) + "…"
+}
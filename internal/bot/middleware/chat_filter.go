@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"tg-bot/internal/botkit"
+)
+
+// InChat silently ignores updates coming from chats other than chatID.
+func InChat(chatID int64, next botkit.ViewFunc) botkit.ViewFunc {
+	return func(ctx context.Context, api *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		if chat := update.FromChat(); chat == nil || chat.ID != chatID {
+			return nil
+		}
+
+		return next(ctx, api, update)
+	}
+}
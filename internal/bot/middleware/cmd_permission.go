@@ -7,8 +7,29 @@ import (
 	"tg-bot/internal/botkit"
 )
 
+// ChannelResolver returns the ID of the chat whose administrators may run the command.
+type ChannelResolver func(ctx context.Context, update tgbotapi.Update) (int64, error)
+
 func AdminOnly(channelID int64, next botkit.ViewFunc) botkit.ViewFunc {
+	return AdminOf(func(context.Context, tgbotapi.Update) (int64, error) {
+		return channelID, nil
+	}, next)
+}
+
+func AdminOf(resolve ChannelResolver, next botkit.ViewFunc) botkit.ViewFunc {
 	return func(ctx context.Context, api *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		channelID, err := resolve(ctx, update)
+		if err != nil {
+			if _, sendErr := api.Send(tgbotapi.NewMessage(
+				update.FromChat().ID,
+				"Failed to resolve channel for this command",
+			)); sendErr != nil {
+				return sendErr
+			}
+
+			return err
+		}
+
 		admins, err := api.GetChatAdministrators(
 			tgbotapi.ChatAdministratorsConfig{
 				ChatConfig: tgbotapi.ChatConfig{
@@ -22,13 +43,13 @@ func AdminOnly(channelID int64, next botkit.ViewFunc) botkit.ViewFunc {
 		}
 
 		for _, admin := range admins {
-			if admin.User.ID == update.Message.From.ID {
+			if admin.User.ID == update.SentFrom().ID {
 				return next(ctx, api, update)
 			}
 		}
 
 		if _, err := api.Send(tgbotapi.NewMessage(
-			update.Message.Chat.ID,
+			update.FromChat().ID,
 			"You are not permitted to use this command",
 		)); err != nil {
 			log.Printf("[ERROR] Failed to send a message via telegram")
@@ -0,0 +1,117 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"tg-bot/internal/botkit"
+	"tg-bot/internal/botkit/markup"
+	"tg-bot/internal/model"
+	"tg-bot/internal/review"
+)
+
+type ReviewStorage interface {
+	ArticleById(ctx context.Context, id int64) (*model.Article, error)
+	SetStatus(ctx context.Context, id int64, status string) error
+	SetGeneratedSummary(ctx context.Context, id int64, summary model.Summary) error
+}
+
+type SummaryGenerator interface {
+	GenerateSummary(ctx context.Context, article model.Article) (model.Summary, error)
+}
+
+func ViewCallbackReview(storage ReviewStorage, generator SummaryGenerator) botkit.ViewFunc {
+	return func(ctx context.Context, api *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		action, articleId, err := review.ParseCallbackData(update.CallbackData())
+		if err != nil {
+			return err
+		}
+
+		article, err := storage.ArticleById(ctx, articleId)
+		if err != nil {
+			return err
+		}
+
+		if article.Status != model.ArticleStatusPendingReview {
+			return answerCallback(api, update, "Article is not awaiting review")
+		}
+
+		var (
+			chatId    = update.CallbackQuery.Message.Chat.ID
+			messageId = update.CallbackQuery.Message.MessageID
+		)
+
+		switch action {
+		case review.ActionApprove, review.ActionReject:
+			status, verdict := model.ArticleStatusApproved, "✅ Approved"
+			if action == review.ActionReject {
+				status, verdict = model.ArticleStatusRejected, "❌ Rejected"
+			}
+
+			if err := storage.SetStatus(ctx, articleId, status); err != nil {
+				return err
+			}
+
+			edit := tgbotapi.NewEditMessageText(chatId, messageId, fmt.Sprintf(
+				"%s\n\n%s",
+				review.Preview(*article, article.PostSummary()),
+				markup.EscapeForMarkdown(verdict+" by "+userName(update.SentFrom())),
+			))
+			edit.ParseMode = tgbotapi.ModeMarkdownV2
+
+			if _, err := api.Send(edit); err != nil {
+				return err
+			}
+
+			return answerCallback(api, update, verdict)
+		case review.ActionRegenerate:
+			summary, err := generator.GenerateSummary(ctx, *article)
+			if err != nil {
+				return err
+			}
+
+			if err := storage.SetGeneratedSummary(ctx, articleId, summary); err != nil {
+				return err
+			}
+
+			edit := tgbotapi.NewEditMessageTextAndMarkup(chatId, messageId,
+				review.Preview(*article, summary.Text), review.Keyboard(articleId))
+			edit.ParseMode = tgbotapi.ModeMarkdownV2
+
+			if _, err := api.Send(edit); err != nil {
+				return err
+			}
+
+			return answerCallback(api, update, "Summary regenerated")
+		case review.ActionEdit:
+			prompt := tgbotapi.NewMessage(chatId, review.EditPrompt(articleId))
+			prompt.ReplyToMessageID = messageId
+			prompt.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true}
+
+			if _, err := api.Send(prompt); err != nil {
+				return err
+			}
+
+			return answerCallback(api, update, "Reply with the new summary")
+		default:
+			return fmt.Errorf("unknown review action %q", action)
+		}
+	}
+}
+
+func answerCallback(api *tgbotapi.BotAPI, update tgbotapi.Update, text string) error {
+	_, err := api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, text))
+	return err
+}
+
+func userName(user *tgbotapi.User) string {
+	if user == nil {
+		return "unknown"
+	}
+
+	if user.UserName != "" {
+		return "@" + user.UserName
+	}
+
+	return user.FirstName
+}
@@ -0,0 +1,72 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"tg-bot/internal/bot/middleware"
+	"tg-bot/internal/botkit"
+	"tg-bot/internal/model"
+)
+
+type ChannelStorage interface {
+	Save(ctx context.Context, channel model.Channel) (int64, error)
+}
+
+type addChannelArgs struct {
+	Name   string `json:"name"`
+	ChatID int64  `json:"chatID"`
+}
+
+func ViewCmdAddChannel(storage ChannelStorage) botkit.ViewFunc {
+	return func(ctx context.Context, api *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[addChannelArgs](update.Message.CommandArguments())
+		if err != nil {
+			_, sendErr := api.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
+				"Failed to parse command arguments"))
+			if sendErr != nil {
+				return sendErr
+			}
+
+			return err
+		}
+
+		channel := model.Channel{
+			Name:   args.Name,
+			ChatID: args.ChatID,
+		}
+
+		channelID, err := storage.Save(ctx, channel)
+		if err != nil {
+			return err
+		}
+
+		var (
+			msgText = fmt.Sprintf(
+				"New channel saved with ID: `%d`\\. Use this ID to link topics to the channel\\.",
+				channelID,
+			)
+			reply = tgbotapi.NewMessage(update.Message.Chat.ID, msgText)
+		)
+
+		reply.ParseMode = tgbotapi.ModeMarkdownV2
+
+		if _, err := api.Send(reply); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// ChatFromAddChannelArgs makes the chat being registered the one whose admins may register it.
+func ChatFromAddChannelArgs() middleware.ChannelResolver {
+	return func(_ context.Context, update tgbotapi.Update) (int64, error) {
+		args, err := botkit.ParseJSON[addChannelArgs](update.Message.CommandArguments())
+		if err != nil {
+			return 0, err
+		}
+
+		return args.ChatID, nil
+	}
+}
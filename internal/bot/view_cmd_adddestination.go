@@ -0,0 +1,71 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"tg-bot/internal/botkit"
+	"tg-bot/internal/model"
+	"tg-bot/internal/publisher"
+)
+
+type DestinationStorage interface {
+	Save(ctx context.Context, destination model.Destination) (int64, error)
+}
+
+type addDestinationArgs struct {
+	TopicID int64  `json:"topicID"`
+	Type    string `json:"type"`
+	URL     string `json:"url"`
+	Token   string `json:"token"`
+	Room    string `json:"room"`
+	Account string `json:"account"`
+}
+
+func ViewCmdAddDestination(storage DestinationStorage) botkit.ViewFunc {
+	return func(ctx context.Context, api *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[addDestinationArgs](update.Message.CommandArguments())
+		if err != nil {
+			_, sendErr := api.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
+				"Failed to parse command arguments"))
+			if sendErr != nil {
+				return sendErr
+			}
+
+			return err
+		}
+
+		destination := model.Destination{
+			TopicID: args.TopicID,
+			Type:    args.Type,
+			URL:     args.URL,
+			Token:   args.Token,
+			Room:    args.Room,
+			Account: args.Account,
+		}
+
+		if err := publisher.Validate(destination); err != nil {
+			_, sendErr := api.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
+				fmt.Sprintf("Invalid destination: %v", err)))
+			return sendErr
+		}
+
+		destinationID, err := storage.Save(ctx, destination)
+		if err != nil {
+			return err
+		}
+
+		reply := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf(
+			"New destination saved with ID: `%d`\\. Articles of topic `%d` will be cross\\-posted to it\\.",
+			destinationID,
+			args.TopicID,
+		))
+		reply.ParseMode = tgbotapi.ModeMarkdownV2
+
+		if _, err := api.Send(reply); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
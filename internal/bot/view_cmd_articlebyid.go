@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"strconv"
+	"tg-bot/internal/botkit"
+	"tg-bot/internal/model"
+)
+
+type ArticleFinder interface {
+	ArticleById(ctx context.Context, id int64) (*model.Article, error)
+	PostsByArticleId(ctx context.Context, articleId int64) ([]model.ArticlePost, error)
+}
+
+func ViewCmdGetArticleById(finder ArticleFinder) botkit.ViewFunc {
+	return func(ctx context.Context, api *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		targetId, err := strconv.ParseInt(update.Message.CommandArguments(),
+			10, 64)
+		if err != nil {
+			_, sendErr := api.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
+				"Failed to parse article id"))
+			if sendErr != nil {
+				return sendErr
+			}
+
+			return err
+		}
+
+		article, err := finder.ArticleById(ctx, targetId)
+		if err != nil {
+			return err
+		}
+
+		posts, err := finder.PostsByArticleId(ctx, targetId)
+		if err != nil {
+			return err
+		}
+
+		reply := tgbotapi.NewMessage(update.Message.Chat.ID, FormatArticle(*article, posts))
+		reply.ParseMode = tgbotapi.ModeMarkdownV2
+		reply.DisableWebPagePreview = true
+
+		if _, err := api.Send(reply); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,38 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"strconv"
+	"tg-bot/internal/botkit"
+)
+
+type DestinationDeleter interface {
+	Delete(ctx context.Context, id int64) error
+}
+
+func ViewCmdDeleteDestination(deleter DestinationDeleter) botkit.ViewFunc {
+	return func(ctx context.Context, api *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		targetId, err := strconv.ParseInt(update.Message.CommandArguments(),
+			10, 64)
+		if err != nil {
+			_, sendErr := api.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
+				"Failed to parse destination id"))
+			if sendErr != nil {
+				return sendErr
+			}
+
+			return err
+		}
+
+		if err := deleter.Delete(ctx, targetId); err != nil {
+			return err
+		}
+
+		_, err = api.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
+			fmt.Sprintf("Destination with ID: %d successfully deleted", targetId)))
+
+		return err
+	}
+}
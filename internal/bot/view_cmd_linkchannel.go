@@ -0,0 +1,82 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"tg-bot/internal/botkit"
+)
+
+type ChannelLinker interface {
+	LinkTopic(ctx context.Context, topicId int64, channelId int64) error
+	UnlinkTopic(ctx context.Context, topicId int64, channelId int64) error
+}
+
+type linkChannelArgs struct {
+	TopicID   int64 `json:"topicID"`
+	ChannelID int64 `json:"channelID"`
+}
+
+func ViewCmdLinkChannel(linker ChannelLinker) botkit.ViewFunc {
+	return func(ctx context.Context, api *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[linkChannelArgs](update.Message.CommandArguments())
+		if err != nil {
+			_, sendErr := api.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
+				"Failed to parse command arguments"))
+			if sendErr != nil {
+				return sendErr
+			}
+
+			return err
+		}
+
+		if err := linker.LinkTopic(ctx, args.TopicID, args.ChannelID); err != nil {
+			return err
+		}
+
+		reply := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf(
+			"Topic `%d` is now posted to channel `%d`",
+			args.TopicID,
+			args.ChannelID,
+		))
+		reply.ParseMode = tgbotapi.ModeMarkdownV2
+
+		if _, err := api.Send(reply); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+func ViewCmdUnlinkChannel(linker ChannelLinker) botkit.ViewFunc {
+	return func(ctx context.Context, api *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[linkChannelArgs](update.Message.CommandArguments())
+		if err != nil {
+			_, sendErr := api.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
+				"Failed to parse command arguments"))
+			if sendErr != nil {
+				return sendErr
+			}
+
+			return err
+		}
+
+		if err := linker.UnlinkTopic(ctx, args.TopicID, args.ChannelID); err != nil {
+			return err
+		}
+
+		reply := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf(
+			"Topic `%d` is no longer posted to channel `%d`",
+			args.TopicID,
+			args.ChannelID,
+		))
+		reply.ParseMode = tgbotapi.ModeMarkdownV2
+
+		if _, err := api.Send(reply); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,45 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/samber/lo"
+	"strings"
+	"tg-bot/internal/botkit"
+	"tg-bot/internal/model"
+)
+
+type ChannelLister interface {
+	Channels(ctx context.Context) ([]model.Channel, error)
+}
+
+func ViewCmdListChannels(lister ChannelLister) botkit.ViewFunc {
+	return func(ctx context.Context, api *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		channels, err := lister.Channels(ctx)
+		if err != nil {
+			return err
+		}
+
+		var (
+			channelInfo = lo.Map(channels, func(channel model.Channel, _ int) string {
+				return FormatChannel(channel)
+			})
+
+			msgText = fmt.Sprintf(
+				"Channels \\(total %d\\):\n\n%s",
+				len(channels),
+				strings.Join(channelInfo, "\n\n"),
+			)
+		)
+
+		reply := tgbotapi.NewMessage(update.Message.Chat.ID, msgText)
+		reply.ParseMode = tgbotapi.ModeMarkdownV2
+
+		if _, err := api.Send(reply); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
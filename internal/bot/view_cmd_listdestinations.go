@@ -0,0 +1,45 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/samber/lo"
+	"strings"
+	"tg-bot/internal/botkit"
+	"tg-bot/internal/model"
+)
+
+type DestinationLister interface {
+	Destinations(ctx context.Context) ([]model.Destination, error)
+}
+
+func ViewCmdListDestinations(lister DestinationLister) botkit.ViewFunc {
+	return func(ctx context.Context, api *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		destinations, err := lister.Destinations(ctx)
+		if err != nil {
+			return err
+		}
+
+		var (
+			destinationInfo = lo.Map(destinations, func(destination model.Destination, _ int) string {
+				return FormatDestination(destination)
+			})
+
+			msgText = fmt.Sprintf(
+				"Destinations \\(total %d\\):\n\n%s",
+				len(destinations),
+				strings.Join(destinationInfo, "\n\n"),
+			)
+		)
+
+		reply := tgbotapi.NewMessage(update.Message.Chat.ID, msgText)
+		reply.ParseMode = tgbotapi.ModeMarkdownV2
+
+		if _, err := api.Send(reply); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
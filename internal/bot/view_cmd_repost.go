@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"strconv"
+	"tg-bot/internal/botkit"
+)
+
+type ArticleReposter interface {
+	Unpost(ctx context.Context, articleId int64) error
+	Repost(ctx context.Context, articleId int64) error
+}
+
+// ViewCmdUnpost deletes the channel messages of an article and marks it as not posted.
+func ViewCmdUnpost(reposter ArticleReposter) botkit.ViewFunc {
+	return viewCmdArticleAction(reposter.Unpost, "Article #%d has been unposted")
+}
+
+// ViewCmdRepost republishes an article with a freshly generated summary.
+func ViewCmdRepost(reposter ArticleReposter) botkit.ViewFunc {
+	return viewCmdArticleAction(reposter.Repost, "Article #%d has been reposted")
+}
+
+func viewCmdArticleAction(
+	action func(ctx context.Context, articleId int64) error,
+	doneFormat string,
+) botkit.ViewFunc {
+	return func(ctx context.Context, api *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		articleId, err := strconv.ParseInt(update.Message.CommandArguments(), 10, 64)
+		if err != nil {
+			_, sendErr := api.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
+				"Failed to parse article id"))
+			if sendErr != nil {
+				return sendErr
+			}
+
+			return err
+		}
+
+		if err := action(ctx, articleId); err != nil {
+			return err
+		}
+
+		_, err = api.Send(tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf(doneFormat, articleId)))
+
+		return err
+	}
+}
@@ -0,0 +1,105 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/samber/lo"
+	"strings"
+	"tg-bot/internal/botkit"
+	"tg-bot/internal/botkit/markup"
+	"tg-bot/internal/model"
+)
+
+type RetentionReporter interface {
+	RetentionReport(ctx context.Context, postedDays int, unpostedDays int) ([]model.RetentionReport, error)
+}
+
+type TopicRetentionSetter interface {
+	SetRetention(ctx context.Context, id int64, postedDays int, unpostedDays int) error
+}
+
+// ViewCmdRetention is a dry run of the cleaner: it reports how many articles of every topic would be
+// deleted on the next clean.
+func ViewCmdRetention(reporter RetentionReporter, postedDays int, unpostedDays int) botkit.ViewFunc {
+	return func(ctx context.Context, api *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		reports, err := reporter.RetentionReport(ctx, postedDays, unpostedDays)
+		if err != nil {
+			return err
+		}
+
+		var (
+			reportInfo = lo.Map(reports, func(report model.RetentionReport, _ int) string {
+				return FormatRetentionReport(report)
+			})
+
+			msgText = fmt.Sprintf(
+				"Articles to be deleted on the next clean:\n\n%s",
+				strings.Join(reportInfo, "\n\n"),
+			)
+		)
+
+		reply := tgbotapi.NewMessage(update.Message.Chat.ID, msgText)
+		reply.ParseMode = tgbotapi.ModeMarkdownV2
+
+		if _, err := api.Send(reply); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// ViewCmdSetRetention overrides the retention of a topic. A retention shorter than minDays is rejected,
+// since deleted articles would be fetched and posted or queued again; zero falls back to the default.
+func ViewCmdSetRetention(setter TopicRetentionSetter, minDays int) botkit.ViewFunc {
+	type setRetentionArgs struct {
+		TopicID      int64 `json:"topicID"`
+		PostedDays   int   `json:"postedDays"`
+		UnpostedDays int   `json:"unpostedDays"`
+	}
+
+	return func(ctx context.Context, api *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[setRetentionArgs](update.Message.CommandArguments())
+		if err != nil {
+			_, sendErr := api.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
+				"Failed to parse command arguments"))
+			if sendErr != nil {
+				return sendErr
+			}
+
+			return err
+		}
+
+		if args.PostedDays < 0 || args.UnpostedDays < 0 {
+			_, sendErr := api.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
+				"Retention days must not be negative"))
+
+			return sendErr
+		}
+
+		if (args.PostedDays != 0 && args.PostedDays < minDays) || (args.UnpostedDays != 0 && args.UnpostedDays < minDays) {
+			_, sendErr := api.Send(tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf(
+				"Retention must be at least %d days, the articles are looked up for posting that far back",
+				minDays,
+			)))
+
+			return sendErr
+		}
+
+		if err := setter.SetRetention(ctx, args.TopicID, args.PostedDays, args.UnpostedDays); err != nil {
+			return err
+		}
+
+		reply := tgbotapi.NewMessage(update.Message.Chat.ID, markup.EscapeForMarkdown(
+			fmt.Sprintf("Retention of topic %d set", args.TopicID),
+		))
+		reply.ParseMode = tgbotapi.ModeMarkdownV2
+
+		if _, err := api.Send(reply); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
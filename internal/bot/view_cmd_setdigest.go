@@ -0,0 +1,81 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"tg-bot/internal/botkit"
+	"tg-bot/internal/botkit/markup"
+	"tg-bot/internal/model"
+	"tg-bot/internal/schedule"
+	"time"
+)
+
+const defaultDigestSize = 10
+
+type ChannelDigestSetter interface {
+	SetDigest(ctx context.Context, channel model.Channel) error
+}
+
+func ViewCmdSetDigest(setter ChannelDigestSetter) botkit.ViewFunc {
+	type setDigestArgs struct {
+		ChannelID int64  `json:"channelID"`
+		Mode      string `json:"mode"`
+		Time      string `json:"time"`
+		Weekday   int    `json:"weekday"`
+		Size      int    `json:"size"`
+		Narrative bool   `json:"narrative"`
+	}
+
+	return func(ctx context.Context, api *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[setDigestArgs](update.Message.CommandArguments())
+		if err != nil {
+			_, sendErr := api.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
+				"Failed to parse command arguments"))
+			if sendErr != nil {
+				return sendErr
+			}
+
+			return err
+		}
+
+		channel := model.Channel{
+			ID:              args.ChannelID,
+			DigestMode:      args.Mode,
+			DigestTime:      args.Time,
+			DigestWeekday:   time.Weekday(args.Weekday % 7),
+			DigestSize:      args.Size,
+			DigestNarrative: args.Narrative,
+		}
+
+		if channel.DigestTime == "" {
+			channel.DigestTime = "09:00"
+		}
+
+		if channel.DigestSize <= 0 {
+			channel.DigestSize = defaultDigestSize
+		}
+
+		if err := schedule.ValidateDigest(channel.DigestMode, channel.DigestTime); err != nil {
+			_, sendErr := api.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
+				fmt.Sprintf("Invalid digest settings: %v", err)))
+
+			return sendErr
+		}
+
+		if err := setter.SetDigest(ctx, channel); err != nil {
+			return err
+		}
+
+		reply := tgbotapi.NewMessage(update.Message.Chat.ID, markup.EscapeForMarkdown(
+			fmt.Sprintf("Digest settings of channel %d updated", args.ChannelID),
+		))
+		reply.ParseMode = tgbotapi.ModeMarkdownV2
+
+		if _, err := api.Send(reply); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
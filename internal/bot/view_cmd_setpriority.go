@@ -0,0 +1,64 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"tg-bot/internal/botkit"
+	"tg-bot/internal/botkit/markup"
+)
+
+type SourcePrioritySetter interface {
+	SetPriority(ctx context.Context, id int64, priority float64) error
+}
+
+func ViewCmdSetPriority(setter SourcePrioritySetter) botkit.ViewFunc {
+	type setPriorityArgs struct {
+		SourceID int64   `json:"sourceID"`
+		Priority float64 `json:"priority"`
+	}
+
+	return func(ctx context.Context, api *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[setPriorityArgs](update.Message.CommandArguments())
+		if err != nil {
+			_, sendErr := api.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
+				"Failed to parse command arguments"))
+			if sendErr != nil {
+				return sendErr
+			}
+
+			return err
+		}
+
+		if args.Priority <= 0 {
+			_, sendErr := api.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
+				"Priority must be positive"))
+
+			return sendErr
+		}
+
+		if err := setter.SetPriority(ctx, args.SourceID, args.Priority); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				_, sendErr := api.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
+					fmt.Sprintf("Source %d not found", args.SourceID)))
+
+				return sendErr
+			}
+
+			return err
+		}
+
+		reply := tgbotapi.NewMessage(update.Message.Chat.ID, markup.EscapeForMarkdown(
+			fmt.Sprintf("Priority of source %d set to %.2f", args.SourceID, args.Priority),
+		))
+		reply.ParseMode = tgbotapi.ModeMarkdownV2
+
+		if _, err := api.Send(reply); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
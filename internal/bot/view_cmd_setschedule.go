@@ -0,0 +1,68 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"tg-bot/internal/botkit"
+	"tg-bot/internal/botkit/markup"
+	"tg-bot/internal/model"
+	"tg-bot/internal/schedule"
+)
+
+type ChannelScheduler interface {
+	SetSchedule(ctx context.Context, channel model.Channel) error
+}
+
+func ViewCmdSetSchedule(scheduler ChannelScheduler) botkit.ViewFunc {
+	type setScheduleArgs struct {
+		ChannelID       int64  `json:"channelID"`
+		Schedule        string `json:"schedule"`
+		QuietHours      string `json:"quietHours"`
+		Timezone        string `json:"timezone"`
+		MaxPostsPerHour int    `json:"maxPostsPerHour"`
+	}
+
+	return func(ctx context.Context, api *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[setScheduleArgs](update.Message.CommandArguments())
+		if err != nil {
+			_, sendErr := api.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
+				"Failed to parse command arguments"))
+			if sendErr != nil {
+				return sendErr
+			}
+
+			return err
+		}
+
+		channel := model.Channel{
+			ID:              args.ChannelID,
+			Timezone:        args.Timezone,
+			Schedule:        args.Schedule,
+			QuietHours:      args.QuietHours,
+			MaxPostsPerHour: args.MaxPostsPerHour,
+		}
+
+		if _, err := schedule.New(args.Timezone, args.Schedule, args.QuietHours, args.MaxPostsPerHour); err != nil {
+			_, sendErr := api.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
+				fmt.Sprintf("Invalid schedule: %v", err)))
+
+			return sendErr
+		}
+
+		if err := scheduler.SetSchedule(ctx, channel); err != nil {
+			return err
+		}
+
+		reply := tgbotapi.NewMessage(update.Message.Chat.ID, markup.EscapeForMarkdown(
+			fmt.Sprintf("Schedule of channel %d updated", args.ChannelID),
+		))
+		reply.ParseMode = tgbotapi.ModeMarkdownV2
+
+		if _, err := api.Send(reply); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
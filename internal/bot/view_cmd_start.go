@@ -15,8 +15,30 @@ func ViewCmdStart() botkit.ViewFunc {
 			"\n- /deletesource {sourceId} - delete source by id" +
 			"\n- /sourcebyid {sourceId} - get source by id" +
 			"\n- /sourcesbytopicid {topicId} - get sources by topic id" +
+			"\n- /setpriority {\"sourceID\": source-id,\"priority\": 1.5} - set source priority" +
+			"\n- /article {articleId} - get article with its stored summary and posts" +
+			"\n- /unpost {articleId} - delete article from channels and mark it as not posted" +
+			"\n- /repost {articleId} - post article again with a freshly generated summary" +
 			"\n- /topics - get all topics" +
-			"\n- /addtopic {\"name\": \"topicName\",\"description\": \"description\"} - add new topic",
+			"\n- /addtopic {\"name\": \"topicName\",\"description\": \"description\"} - add new topic" +
+			"\n- /retention - report how many articles of every topic the next clean deletes" +
+			"\n- /setretention {\"topicID\": topic-id,\"postedDays\": 30,\"unpostedDays\": 7} - keep topic articles " +
+			"for these days instead of the configured ones, 0 keeps the configured value" +
+			"\n- /destinations - get all cross-post destinations" +
+			"\n- /adddestination {\"topicID\": topic-id,\"type\": \"slack\",\"url\": \"webhook-url\"} - cross-post topic " +
+			"to a webhook, slack, discord, matrix, mastodon or bluesky destination, matrix also takes \"token\" and " +
+			"\"room\", mastodon takes the server url and \"token\", bluesky takes \"account\" and an app password " +
+			"as \"token\"" +
+			"\n- /deletedestination {destinationId} - delete destination by id" +
+			"\n- /channels - get all channels" +
+			"\n- /addchannel {\"name\": \"channelName\",\"chatID\": chat-id} - add new channel" +
+			"\n- /linkchannel {\"topicID\": topic-id,\"channelID\": channel-id} - post topic to channel" +
+			"\n- /unlinkchannel {\"topicID\": topic-id,\"channelID\": channel-id} - stop posting topic to channel" +
+			"\n- /setschedule {\"channelID\": channel-id,\"schedule\": \"* 9-21 * * *\",\"quietHours\": \"23:00-07:00\"," +
+			"\"timezone\": \"Europe/Berlin\",\"maxPostsPerHour\": 4} - set channel posting schedule" +
+			"\n- /setdigest {\"channelID\": channel-id,\"mode\": \"daily\",\"time\": \"09:00\",\"weekday\": 1," +
+			"\"size\": 10,\"narrative\": true} - post a daily or weekly digest instead of single articles, " +
+			"empty mode disables it, narrative merges related stories",
 		)
 
 		reply := tgbotapi.NewMessage(update.Message.Chat.ID, msgText)
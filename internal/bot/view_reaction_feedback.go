@@ -0,0 +1,110 @@
+package bot
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/samber/lo"
+	"tg-bot/internal/botkit"
+	"tg-bot/internal/model"
+)
+
+const (
+	likeEmoji    = "👍"
+	dislikeEmoji = "👎"
+)
+
+type PostReactionStorage interface {
+	PostsByMessage(ctx context.Context, chatId int64, messageId int) ([]model.ArticlePost, error)
+	SetPostReactions(ctx context.Context, postId int64, likes int, dislikes int) error
+}
+
+type SourcePriorityAdjuster interface {
+	AdjustArticleSourcePriority(ctx context.Context, articleId int64, delta float64, minPriority float64, maxPriority float64) error
+}
+
+// ViewReactionFeedback moves the priority of an article's source by step for every 👍 and against it
+// for every 👎 the posted article receives, keeping it within [minPriority, maxPriority]. Reactions on
+// a digest are ignored, since they can't be told apart between the sources of its articles.
+func ViewReactionFeedback(
+	posts PostReactionStorage,
+	sources SourcePriorityAdjuster,
+	step float64,
+	minPriority float64,
+	maxPriority float64,
+) botkit.ReactionFunc {
+	return func(ctx context.Context, api *tgbotapi.BotAPI, update botkit.Update) error {
+		var (
+			chatId    int64
+			messageId int
+		)
+
+		switch {
+		case update.MessageReactionCount != nil:
+			chatId, messageId = update.MessageReactionCount.Chat.ID, update.MessageReactionCount.MessageID
+		case update.MessageReaction != nil:
+			chatId, messageId = update.MessageReaction.Chat.ID, update.MessageReaction.MessageID
+		default:
+			return nil
+		}
+
+		messagePosts, err := posts.PostsByMessage(ctx, chatId, messageId)
+		if err != nil || len(messagePosts) != 1 {
+			return err
+		}
+
+		post := messagePosts[0]
+
+		likes, dislikes := countReactions(post, update)
+
+		if likes == post.Likes && dislikes == post.Dislikes {
+			return nil
+		}
+
+		if err := posts.SetPostReactions(ctx, post.ID, likes, dislikes); err != nil {
+			return err
+		}
+
+		delta := (likes - post.Likes) - (dislikes - post.Dislikes)
+		if delta == 0 {
+			return nil
+		}
+
+		return sources.AdjustArticleSourcePriority(ctx, post.ArticleID, step*float64(delta), minPriority, maxPriority)
+	}
+}
+
+// countReactions returns the new 👍 and 👎 totals of the post. Channels report anonymous totals,
+// while chats with visible reactions report changes of a single user.
+func countReactions(post model.ArticlePost, update botkit.Update) (int, int) {
+	if update.MessageReactionCount != nil {
+		return totalCount(update.MessageReactionCount.Reactions, likeEmoji),
+			totalCount(update.MessageReactionCount.Reactions, dislikeEmoji)
+	}
+
+	var (
+		oldReaction = update.MessageReaction.OldReaction
+		newReaction = update.MessageReaction.NewReaction
+		likes       = post.Likes + hasEmoji(newReaction, likeEmoji) - hasEmoji(oldReaction, likeEmoji)
+		dislikes    = post.Dislikes + hasEmoji(newReaction, dislikeEmoji) - hasEmoji(oldReaction, dislikeEmoji)
+	)
+
+	return max(likes, 0), max(dislikes, 0)
+}
+
+func totalCount(reactions []botkit.ReactionCount, emoji string) int {
+	reaction, _ := lo.Find(reactions, func(reaction botkit.ReactionCount) bool {
+		return reaction.Type.Type == botkit.ReactionTypeEmoji && reaction.Type.Emoji == emoji
+	})
+
+	return reaction.TotalCount
+}
+
+func hasEmoji(reactions []botkit.ReactionType, emoji string) int {
+	if lo.ContainsBy(reactions, func(reaction botkit.ReactionType) bool {
+		return reaction.Type == botkit.ReactionTypeEmoji && reaction.Emoji == emoji
+	}) {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,73 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"strings"
+	"tg-bot/internal/botkit"
+	"tg-bot/internal/model"
+	"tg-bot/internal/review"
+)
+
+type SummaryEditor interface {
+	ArticleById(ctx context.Context, id int64) (*model.Article, error)
+	ArticleByReviewMessage(ctx context.Context, messageId int) (*model.Article, error)
+	SetEditedSummary(ctx context.Context, id int64, summary string) error
+}
+
+// ViewReplyEditSummary replaces the summary of a pending article with the text of a reply to its
+// preview or to its edit prompt. The generated summary is kept next to the edited one.
+func ViewReplyEditSummary(storage SummaryEditor) botkit.ViewFunc {
+	return func(ctx context.Context, api *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		repliedTo := update.Message.ReplyToMessage
+		if repliedTo.From == nil || repliedTo.From.ID != api.Self.ID {
+			return nil
+		}
+
+		article, err := findRepliedArticle(ctx, storage, repliedTo)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+
+			return err
+		}
+
+		if article.Status != model.ArticleStatusPendingReview {
+			_, err := api.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "Article is not awaiting review"))
+			return err
+		}
+
+		summary := strings.TrimSpace(update.Message.Text)
+		if summary == "" {
+			_, err := api.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "Summary can't be empty"))
+			return err
+		}
+
+		if err := storage.SetEditedSummary(ctx, article.ID, summary); err != nil {
+			return err
+		}
+
+		edit := tgbotapi.NewEditMessageTextAndMarkup(update.Message.Chat.ID, article.ReviewMessageID,
+			review.Preview(*article, summary), review.Keyboard(article.ID))
+		edit.ParseMode = tgbotapi.ModeMarkdownV2
+
+		if _, err := api.Send(edit); err != nil {
+			return err
+		}
+
+		_, err = api.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "Summary updated"))
+
+		return err
+	}
+}
+
+func findRepliedArticle(ctx context.Context, storage SummaryEditor, repliedTo *tgbotapi.Message) (*model.Article, error) {
+	if articleId, ok := review.ParseEditPrompt(repliedTo.Text); ok {
+		return storage.ArticleById(ctx, articleId)
+	}
+
+	return storage.ArticleByReviewMessage(ctx, repliedTo.MessageID)
+}
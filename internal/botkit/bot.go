@@ -2,17 +2,32 @@ package botkit
 
 import (
 	"context"
+	"encoding/json"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"log"
 	"runtime/debug"
+	"strings"
+	"tg-bot/internal/health"
 	"time"
 )
 
-const updateTimeout int = 60
+const (
+	updateTimeout     int = 60
+	pollRetryTimeout      = 3 * time.Second
+	handleTimeout         = 5 * time.Second
+	longHandleTimeout     = 2 * time.Minute
+)
+
+var allowedUpdates = []string{"message", "callback_query", "message_reaction", "message_reaction_count"}
 
 type Bot struct {
-	api      *tgbotapi.BotAPI
-	cmdViews map[string]ViewFunc
+	api           *tgbotapi.BotAPI
+	cmdViews      map[string]ViewFunc
+	longCmds      map[string]bool
+	callbackViews map[string]ViewFunc
+	replyViews    []ViewFunc
+	reactionViews []ReactionFunc
+	heartbeat     health.Heartbeat
 }
 
 func NewBot(api *tgbotapi.BotAPI) *Bot {
@@ -21,6 +36,8 @@ func NewBot(api *tgbotapi.BotAPI) *Bot {
 
 type ViewFunc func(ctx context.Context, api *tgbotapi.BotAPI, update tgbotapi.Update) error
 
+type ReactionFunc func(ctx context.Context, api *tgbotapi.BotAPI, update Update) error
+
 func (b *Bot) RegisterCmdView(cmd string, view ViewFunc) {
 	if b.cmdViews == nil {
 		b.cmdViews = make(map[string]ViewFunc)
@@ -29,16 +46,43 @@ func (b *Bot) RegisterCmdView(cmd string, view ViewFunc) {
 	b.cmdViews[cmd] = view
 }
 
-func (b *Bot) Run(ctx context.Context) error {
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = updateTimeout
+// RegisterLongCmdView handles a command that may take as long as a button press, e.g. one that calls
+// the AI client.
+func (b *Bot) RegisterLongCmdView(cmd string, view ViewFunc) {
+	b.RegisterCmdView(cmd, view)
+
+	if b.longCmds == nil {
+		b.longCmds = make(map[string]bool)
+	}
+
+	b.longCmds[cmd] = true
+}
+
+// RegisterCallbackView handles inline button presses whose data starts with "prefix:".
+func (b *Bot) RegisterCallbackView(prefix string, view ViewFunc) {
+	if b.callbackViews == nil {
+		b.callbackViews = make(map[string]ViewFunc)
+	}
 
-	updates := b.api.GetUpdatesChan(u)
+	b.callbackViews[prefix] = view
+}
+
+// RegisterReplyView handles messages that are not commands but replies to other messages.
+func (b *Bot) RegisterReplyView(view ViewFunc) {
+	b.replyViews = append(b.replyViews, view)
+}
+
+func (b *Bot) RegisterReactionView(view ReactionFunc) {
+	b.reactionViews = append(b.reactionViews, view)
+}
+
+func (b *Bot) Run(ctx context.Context) error {
+	updates := b.pollUpdates(ctx)
 
 	for {
 		select {
 		case update := <-updates:
-			updateCtx, updateCancel := context.WithTimeout(ctx, 5*time.Second)
+			updateCtx, updateCancel := context.WithTimeout(ctx, b.handleTimeout(update))
 			b.handleUpdate(updateCtx, update)
 			updateCancel()
 		case <-ctx.Done():
@@ -47,6 +91,83 @@ func (b *Bot) Run(ctx context.Context) error {
 	}
 }
 
+func (b *Bot) handleTimeout(update Update) time.Duration {
+	if update.CallbackQuery != nil {
+		return longHandleTimeout
+	}
+
+	if update.Message != nil && update.Message.IsCommand() && b.longCmds[update.Message.Command()] {
+		return longHandleTimeout
+	}
+
+	return handleTimeout
+}
+
+// pollUpdates replaces tgbotapi.GetUpdatesChan to receive update types the library can't decode.
+func (b *Bot) pollUpdates(ctx context.Context) <-chan Update {
+	updates := make(chan Update, 100)
+
+	go func() {
+		config := tgbotapi.NewUpdate(0)
+		config.Timeout = updateTimeout
+		config.AllowedUpdates = allowedUpdates
+
+		b.heartbeat.Beat()
+
+		for ctx.Err() == nil {
+			batch, err := b.getUpdates(config)
+			if err != nil {
+				log.Printf("[ERROR] failed to get updates, retrying in %v: %v", pollRetryTimeout, err)
+
+				select {
+				case <-ctx.Done():
+				case <-time.After(pollRetryTimeout):
+				}
+
+				continue
+			}
+
+			b.heartbeat.Beat()
+
+			for _, update := range batch {
+				if update.UpdateID < config.Offset {
+					continue
+				}
+
+				config.Offset = update.UpdateID + 1
+
+				select {
+				case updates <- update:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return updates
+}
+
+// LastPoll returns when updates were last received from Telegram. Long polling returns at least
+// once a minute, even without updates.
+func (b *Bot) LastPoll() time.Time {
+	return b.heartbeat.Last()
+}
+
+func (b *Bot) getUpdates(config tgbotapi.UpdateConfig) ([]Update, error) {
+	resp, err := b.api.Request(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []Update
+	if err := json.Unmarshal(resp.Result, &updates); err != nil {
+		return nil, err
+	}
+
+	return updates, nil
+}
+
 func (b *Bot) SendMessage(chatId int64, message string) {
 	if _, err := b.api.Send(
 		tgbotapi.NewMessage(chatId, message),
@@ -55,19 +176,32 @@ func (b *Bot) SendMessage(chatId int64, message string) {
 	}
 }
 
-func (b *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) {
+func (b *Bot) handleUpdate(ctx context.Context, update Update) {
 	defer func() {
 		if p := recover(); p != nil {
 			log.Printf("[ERROR] panic recoverd: %v\n%s", p, string(debug.Stack()))
 		}
 	}()
 
-	if update.Message == nil || !update.Message.IsCommand() {
+	if update.MessageReaction != nil || update.MessageReactionCount != nil {
+		b.handleReaction(ctx, update)
+		return
+	}
+
+	if update.CallbackQuery != nil {
+		b.handleCallback(ctx, update.Update)
+		return
+	}
+
+	if update.Message == nil {
 		return
 	}
 
 	if !update.Message.IsCommand() {
-		b.SendMessage(update.Message.Chat.ID, "Your message is not a command")
+		if update.Message.ReplyToMessage != nil {
+			b.handleReply(ctx, update.Update)
+		}
+
 		return
 	}
 
@@ -79,8 +213,42 @@ func (b *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) {
 		return
 	}
 
-	if err := cmdView(ctx, b.api, update); err != nil {
+	if err := cmdView(ctx, b.api, update.Update); err != nil {
 		log.Printf("[ERROR] failed to handle update: %v", err)
 		b.SendMessage(update.Message.Chat.ID, "internal error")
 	}
 }
+
+func (b *Bot) handleReaction(ctx context.Context, update Update) {
+	for _, view := range b.reactionViews {
+		if err := view(ctx, b.api, update); err != nil {
+			log.Printf("[ERROR] failed to handle reaction: %v", err)
+		}
+	}
+}
+
+func (b *Bot) handleCallback(ctx context.Context, update tgbotapi.Update) {
+	prefix, _, _ := strings.Cut(update.CallbackData(), ":")
+
+	callbackView, ok := b.callbackViews[prefix]
+	if !ok {
+		return
+	}
+
+	if err := callbackView(ctx, b.api, update); err != nil {
+		log.Printf("[ERROR] failed to handle callback: %v", err)
+
+		if _, err := b.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "internal error")); err != nil {
+			log.Printf("[ERROR] failed to answer callback: %v", err)
+		}
+	}
+}
+
+func (b *Bot) handleReply(ctx context.Context, update tgbotapi.Update) {
+	for _, view := range b.replyViews {
+		if err := view(ctx, b.api, update); err != nil {
+			log.Printf("[ERROR] failed to handle reply: %v", err)
+			b.SendMessage(update.Message.Chat.ID, "internal error")
+		}
+	}
+}
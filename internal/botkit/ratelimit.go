@@ -0,0 +1,235 @@
+package botkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"golang.org/x/time/rate"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"tg-bot/internal/metrics"
+	"time"
+)
+
+// Telegram limits: about 30 messages per second overall, one message per second in a chat and
+// 20 messages per minute in a group or channel.
+const (
+	globalMessagesPerSecond = 30
+	chatMessageInterval     = time.Second
+	groupMessageInterval    = 3 * time.Second
+	groupMessagesBurst      = 20
+	maxRateLimitRetries     = 3
+)
+
+// limitedMethodPrefixes are the Bot API methods that post or change messages and count towards the limits.
+var limitedMethodPrefixes = []string{"send", "edit", "forward", "copy"}
+
+// RateLimitedClient is the HTTP client of the Bot API that keeps messages within Telegram limits.
+// Messages wait in line for their chat and for the global limit, and a 429 response holds the chat
+// back for the retry_after period before the message is sent again. Being the client of the BotAPI,
+// it is shared by the notifier and all views.
+type RateLimitedClient struct {
+	client *http.Client
+	global *rate.Limiter
+
+	mu    sync.Mutex
+	chats map[string]*chatLimit
+}
+
+type chatLimit struct {
+	limiters     []*rate.Limiter
+	blockedUntil time.Time
+}
+
+func NewRateLimitedClient(client *http.Client) *RateLimitedClient {
+	return &RateLimitedClient{
+		client: client,
+		global: rate.NewLimiter(globalMessagesPerSecond, globalMessagesPerSecond),
+		chats:  make(map[string]*chatLimit),
+	}
+}
+
+// Do sends the request, counting failed requests in the metrics.
+func (c *RateLimitedClient) Do(req *http.Request) (*http.Response, error) {
+	method := path.Base(req.URL.Path)
+
+	resp, err := c.do(req, method)
+	switch {
+	case err != nil:
+		metrics.TelegramErrors.WithLabelValues(method, "0").Inc()
+	case resp.StatusCode >= http.StatusBadRequest:
+		metrics.TelegramErrors.WithLabelValues(method, strconv.Itoa(resp.StatusCode)).Inc()
+	}
+
+	return resp, err
+}
+
+func (c *RateLimitedClient) do(req *http.Request, method string) (*http.Response, error) {
+	if !isLimitedMethod(method) {
+		return c.client.Do(req)
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	chatId := chatIdOf(req, body)
+
+	for attempt := 0; ; attempt++ {
+		if err := c.wait(req.Context(), chatId); err != nil {
+			return nil, err
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		retryAfter, err := retryAfterOf(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if retryAfter == 0 || attempt == maxRateLimitRetries {
+			return resp, nil
+		}
+
+		_ = resp.Body.Close()
+
+		log.Printf("[WARN] Telegram rate limit hit in chat %q, retrying in %s", chatId, retryAfter)
+
+		c.block(chatId, retryAfter)
+	}
+}
+
+// wait blocks until the message may be sent to the chat.
+func (c *RateLimitedClient) wait(ctx context.Context, chatId string) error {
+	limit := c.chatLimit(chatId)
+
+	c.mu.Lock()
+	blockedFor := time.Until(limit.blockedUntil)
+	c.mu.Unlock()
+
+	if blockedFor > 0 {
+		timer := time.NewTimer(blockedFor)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	for _, limiter := range limit.limiters {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	return c.global.Wait(ctx)
+}
+
+func (c *RateLimitedClient) block(chatId string, retryAfter time.Duration) {
+	limit := c.chatLimit(chatId)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	limit.blockedUntil = time.Now().Add(retryAfter)
+}
+
+func (c *RateLimitedClient) chatLimit(chatId string) *chatLimit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	limit, ok := c.chats[chatId]
+	if !ok {
+		limit = &chatLimit{}
+
+		if chatId != "" {
+			limit.limiters = append(limit.limiters, rate.NewLimiter(rate.Every(chatMessageInterval), 1))
+		}
+
+		if isGroupChat(chatId) {
+			limit.limiters = append(limit.limiters, rate.NewLimiter(rate.Every(groupMessageInterval), groupMessagesBurst))
+		}
+
+		c.chats[chatId] = limit
+	}
+
+	return limit
+}
+
+func isLimitedMethod(method string) bool {
+	for _, prefix := range limitedMethodPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isGroupChat reports whether the chat is a group or a channel: their IDs are negative and channels
+// may also be addressed by @username.
+func isGroupChat(chatId string) bool {
+	return strings.HasPrefix(chatId, "-") || strings.HasPrefix(chatId, "@")
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	defer req.Body.Close()
+
+	return io.ReadAll(req.Body)
+}
+
+// chatIdOf returns the chat of a form encoded request. Uploads are multipart and only count towards
+// the global limit.
+func chatIdOf(req *http.Request, body []byte) string {
+	if !strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		return ""
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return ""
+	}
+
+	return values.Get("chat_id")
+}
+
+// retryAfterOf returns how long Telegram asks to wait after a 429 response, leaving the body readable.
+func retryAfterOf(resp *http.Response) (time.Duration, error) {
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return 0, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	var apiResp tgbotapi.APIResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil || apiResp.Parameters == nil {
+		return 0, nil
+	}
+
+	return time.Duration(apiResp.Parameters.RetryAfter) * time.Second, nil
+}
@@ -0,0 +1,67 @@
+package cleaner
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+type ArticleCleaner interface {
+	DeleteExpired(ctx context.Context, postedDays int, unpostedDays int) (int64, error)
+}
+
+// Cleaner deletes articles past their retention: posted articles after postedDays and articles that
+// were never posted after unpostedDays, unless their topic overrides the retention. Articles are kept
+// for deduplication until then, so the retention should outlast the items in the feeds.
+type Cleaner struct {
+	articles      ArticleCleaner
+	cleanInterval time.Duration
+	postedDays    int
+	unpostedDays  int
+}
+
+func New(articles ArticleCleaner, cleanInterval time.Duration, postedDays int, unpostedDays int) *Cleaner {
+	return &Cleaner{
+		articles:      articles,
+		cleanInterval: cleanInterval,
+		postedDays:    postedDays,
+		unpostedDays:  unpostedDays,
+	}
+}
+
+// MinRetentionDays is the shortest retention that keeps articles as long as the notifier looks back for
+// articles to post. An article deleted earlier is saved again as a new one when it is still in its feed,
+// and is posted a second time if it was posted, or queued again if it was never posted or given up.
+func MinRetentionDays(lookupWindow time.Duration) int {
+	return int((lookupWindow + day - 1) / day)
+}
+
+func (c *Cleaner) Start(ctx context.Context) error {
+	ticker := time.NewTicker(c.cleanInterval)
+	defer ticker.Stop()
+
+	c.clean(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			c.clean(ctx)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *Cleaner) clean(ctx context.Context) {
+	deleted, err := c.articles.DeleteExpired(ctx, c.postedDays, c.unpostedDays)
+	if err != nil {
+		log.Printf("[ERROR] Failed to delete expired articles: %v", err)
+		return
+	}
+
+	if deleted > 0 {
+		log.Printf("deleted %d expired articles", deleted)
+	}
+}
@@ -0,0 +1,95 @@
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"tg-bot/internal/model"
+	"time"
+)
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+type Atom struct {
+	XMLName  xml.Name `xml:"feed"`
+	XMLNS    string   `xml:"xmlns,attr"`
+	ID       string   `xml:"id"`
+	Title    string   `xml:"title"`
+	Subtitle string   `xml:"subtitle,omitempty"`
+	Updated  string   `xml:"updated"`
+	Author   Author   `xml:"author"`
+	Links    []Link   `xml:"link"`
+	Entries  []Entry  `xml:"entry"`
+}
+
+type Author struct {
+	Name string `xml:"name"`
+}
+
+type Link struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type Entry struct {
+	ID        string `xml:"id"`
+	Title     string `xml:"title"`
+	Links     []Link `xml:"link"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Summary   string `xml:"summary,omitempty"`
+}
+
+// NewAtom builds a feed of posted articles with the summaries they were published with. The feed is
+// updated when its latest article was posted.
+func NewAtom(id string, title string, subtitle string, selfURL string, articles []model.Article) Atom {
+	atom := Atom{
+		XMLNS:    atomNamespace,
+		ID:       id,
+		Title:    title,
+		Subtitle: subtitle,
+		Updated:  formatTime(time.Now()),
+		Author:   Author{Name: title},
+		Links:    []Link{{Rel: "self", Href: selfURL}},
+		Entries:  make([]Entry, 0, len(articles)),
+	}
+
+	if len(articles) > 0 {
+		atom.Updated = formatTime(postedAt(articles[0]))
+	}
+
+	for _, article := range articles {
+		atom.Entries = append(atom.Entries, Entry{
+			ID:        fmt.Sprintf("urn:tg-bot:article:%d", article.ID),
+			Title:     article.Title,
+			Links:     []Link{{Rel: "alternate", Href: article.Link}},
+			Published: formatTime(article.PublishedAt),
+			Updated:   formatTime(postedAt(article)),
+			Summary:   article.PostSummary(),
+		})
+	}
+
+	return atom
+}
+
+// Marshal returns the feed as an XML document.
+func (a Atom) Marshal() ([]byte, error) {
+	body, err := xml.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
+
+// postedAt falls back to the publication time for articles delivered to some but not all of their channels.
+func postedAt(article model.Article) time.Time {
+	if article.PostedAt.IsZero() {
+		return article.PublishedAt
+	}
+
+	return article.PostedAt
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
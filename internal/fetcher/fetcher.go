@@ -2,9 +2,13 @@ package fetcher
 
 import (
 	"context"
+	"errors"
 	set "github.com/deckarep/golang-set/v2"
+	"log"
 	"strings"
 	"sync"
+	"tg-bot/internal/health"
+	"tg-bot/internal/metrics"
 	"tg-bot/internal/model"
 	"tg-bot/internal/source"
 	"time"
@@ -16,6 +20,12 @@ type ArticleStorage interface {
 
 type SourceProvider interface {
 	Sources(ctx context.Context) ([]model.Source, error)
+	SetFetchResult(ctx context.Context, id int64, fetchedAt time.Time, fetchErr string) error
+}
+
+// FilterProvider returns the filter keywords managed at runtime, in addition to the configured ones.
+type FilterProvider interface {
+	Keywords(ctx context.Context) ([]string, error)
 }
 
 type Source interface {
@@ -27,22 +37,29 @@ type Source interface {
 type Fetcher struct {
 	articles ArticleStorage
 	sources  SourceProvider
+	filters  FilterProvider
 
 	fetchInterval  time.Duration
 	filterKeywords []string
+	heartbeat      health.Heartbeat
+	// trigger holds a fetch requested between ticks, see Trigger.
+	trigger chan struct{}
 }
 
 func New(
 	articleStorage ArticleStorage,
 	sourceProvider SourceProvider,
+	filterProvider FilterProvider,
 	fetchInterval time.Duration,
 	filterKeywords []string,
 ) *Fetcher {
 	return &Fetcher{
 		articles:       articleStorage,
 		sources:        sourceProvider,
+		filters:        filterProvider,
 		fetchInterval:  fetchInterval,
 		filterKeywords: filterKeywords,
+		trigger:        make(chan struct{}, 1),
 	}
 }
 
@@ -50,10 +67,14 @@ func (f *Fetcher) Start(ctx context.Context) error {
 	ticker := time.NewTicker(f.fetchInterval)
 	defer ticker.Stop()
 
+	f.heartbeat.Beat()
+
 	if err := f.Fetch(ctx); err != nil {
 		return err
 	}
 
+	f.heartbeat.Beat()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -62,16 +83,47 @@ func (f *Fetcher) Start(ctx context.Context) error {
 			if err := f.Fetch(ctx); err != nil {
 				return err
 			}
+
+			f.heartbeat.Beat()
+		case <-f.trigger:
+			if err := f.Fetch(ctx); err != nil {
+				return err
+			}
+
+			f.heartbeat.Beat()
 		}
 	}
 }
 
+// Trigger asks the fetch loop to fetch right after the current fetch, if any, instead of waiting for
+// the next tick. It reports false if a fetch is already waiting.
+func (f *Fetcher) Trigger() bool {
+	select {
+	case f.trigger <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// LastTick returns when the fetch loop last started or finished a fetch.
+func (f *Fetcher) LastTick() time.Time {
+	return f.heartbeat.Last()
+}
+
 func (f *Fetcher) Fetch(ctx context.Context) error {
 	sources, err := f.sources.Sources(ctx)
 	if err != nil {
 		return err
 	}
 
+	keywords, err := f.filters.Keywords(ctx)
+	if err != nil {
+		return err
+	}
+
+	keywords = append(keywords, f.filterKeywords...)
+
 	var wg sync.WaitGroup
 
 	for _, val := range sources {
@@ -82,14 +134,17 @@ func (f *Fetcher) Fetch(ctx context.Context) error {
 		go func(source Source) {
 			defer wg.Done()
 
-			items, err := rssSource.Fetch(ctx)
-			if err != nil {
-				return
-			}
+			started := time.Now()
 
-			if err := f.processRSSArticles(ctx, source, items); err != nil {
-				return
+			items, err := source.Fetch(ctx)
+			if err == nil {
+				metrics.FetchedItems.WithLabelValues(source.Name()).Add(float64(len(items)))
+				err = f.processRSSArticles(ctx, source, items, keywords)
 			}
+
+			metrics.FetchDuration.WithLabelValues(source.Name()).Observe(time.Since(started).Seconds())
+
+			f.recordFetchResult(ctx, source, err)
 		}(rssSource)
 	}
 
@@ -98,11 +153,17 @@ func (f *Fetcher) Fetch(ctx context.Context) error {
 	return nil
 }
 
-func (f *Fetcher) processRSSArticles(ctx context.Context, source Source, items []model.RSSArticle) error {
+func (f *Fetcher) processRSSArticles(
+	ctx context.Context,
+	source Source,
+	items []model.RSSArticle,
+	keywords []string,
+) error {
 	for _, item := range items {
 		item.Date = item.Date.UTC()
 
-		if f.itemShouldBeSkipped(item) {
+		if keyword, skip := itemShouldBeSkipped(item, keywords); skip {
+			metrics.SkippedArticles.WithLabelValues(keyword).Inc()
 			continue
 		}
 
@@ -113,6 +174,7 @@ func (f *Fetcher) processRSSArticles(ctx context.Context, source Source, items [
 			Summary:     item.Summary,
 			PublishedAt: item.Date,
 		}); err != nil {
+			metrics.SaveErrors.Inc()
 			return err
 		}
 	}
@@ -120,16 +182,34 @@ func (f *Fetcher) processRSSArticles(ctx context.Context, source Source, items [
 	return nil
 }
 
-func (f *Fetcher) itemShouldBeSkipped(article model.RSSArticle) bool {
+// recordFetchResult stores the outcome of the fetch, so the health of every source can be seen.
+func (f *Fetcher) recordFetchResult(ctx context.Context, source Source, fetchErr error) {
+	if errors.Is(fetchErr, context.Canceled) {
+		return
+	}
+
+	errText := ""
+	if fetchErr != nil {
+		errText = fetchErr.Error()
+		log.Printf("[WARN] Failed to fetch source %q: %v", source.Name(), fetchErr)
+	}
+
+	if err := f.sources.SetFetchResult(ctx, source.ID(), time.Now(), errText); err != nil {
+		log.Printf("[ERROR] Failed to record fetch result of source %q: %v", source.Name(), err)
+	}
+}
+
+// itemShouldBeSkipped returns the filter keyword the article matches, if any.
+func itemShouldBeSkipped(article model.RSSArticle, keywords []string) (string, bool) {
 	categories := set.NewSet[string](article.Categories...)
 
-	for _, keyword := range f.filterKeywords {
+	for _, keyword := range keywords {
 		titleContainsKeyword := strings.Contains(strings.ToLower(article.Title), keyword)
 
 		if categories.Contains(keyword) || titleContainsKeyword {
-			return true
+			return keyword, true
 		}
 	}
 
-	return false
+	return "", false
 }
@@ -0,0 +1,115 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const checkTimeout = 3 * time.Second
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Heartbeat records when a loop last went round, so a stuck or stopped loop can be detected.
+type Heartbeat struct {
+	last atomic.Int64
+}
+
+func (h *Heartbeat) Beat() {
+	h.last.Store(time.Now().UnixNano())
+}
+
+// Last returns the time of the last beat, zero if there was none.
+func (h *Heartbeat) Last() time.Time {
+	last := h.last.Load()
+	if last == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, last)
+}
+
+// Check returns an error when the component is unhealthy.
+type Check func(ctx context.Context) error
+
+// Recent checks that the loop beat within maxAge.
+func Recent(lastBeat func() time.Time, maxAge time.Duration) Check {
+	return func(ctx context.Context) error {
+		last := lastBeat()
+		if last.IsZero() {
+			return fmt.Errorf("not started")
+		}
+
+		if age := time.Since(last); age > maxAge {
+			return fmt.Errorf("last ticked %s ago, expected within %s", age.Round(time.Second), maxAge)
+		}
+
+		return nil
+	}
+}
+
+type ComponentStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+// Checker runs the checks of a set of components.
+type Checker struct {
+	checks map[string]Check
+}
+
+func NewChecker() *Checker {
+	return &Checker{checks: make(map[string]Check)}
+}
+
+func (c *Checker) Add(component string, check Check) {
+	c.checks[component] = check
+}
+
+// Run runs all checks at once. The report is ok only when every component is.
+func (c *Checker) Run(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	report := Report{Status: StatusOK, Components: make(map[string]ComponentStatus, len(c.checks))}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for component, check := range c.checks {
+		wg.Add(1)
+
+		go func(component string, check Check) {
+			defer wg.Done()
+
+			status := ComponentStatus{Status: StatusOK}
+			if err := check(ctx); err != nil {
+				status = ComponentStatus{Status: StatusFail, Error: err.Error()}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Components[component] = status
+			if status.Status != StatusOK {
+				report.Status = StatusFail
+			}
+		}(component, check)
+	}
+
+	wg.Wait()
+
+	return report
+}
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"log"
+	"time"
+)
+
+const (
+	namespace    = "newsbot"
+	queueTimeout = 5 * time.Second
+)
+
+var (
+	FetchedItems = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "fetched_items_total",
+		Help:      "Items fetched from a source.",
+	}, []string{"source"})
+
+	SaveErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "article_save_errors_total",
+		Help:      "Fetched articles that failed to be saved.",
+	})
+
+	FetchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "fetch_duration_seconds",
+		Help:      "Time to fetch and save the items of a source.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"source"})
+
+	SkippedArticles = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "skipped_articles_total",
+		Help:      "Fetched items skipped by a filter keyword.",
+	}, []string{"filter"})
+
+	LLMRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "llm_request_duration_seconds",
+		Help:      "Latency of LLM requests.",
+		Buckets:   []float64{0.5, 1, 2, 5, 10, 20, 30, 60},
+	}, []string{"model"})
+
+	LLMTokens = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_tokens_total",
+		Help:      "Tokens used by LLM requests, by kind: prompt or completion.",
+	}, []string{"model", "kind"})
+
+	PostsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "posts_sent_total",
+		Help:      "Articles posted to Telegram or cross-posted to a destination type.",
+	}, []string{"target"})
+
+	TelegramErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "telegram_errors_total",
+		Help:      "Failed Bot API requests by method and HTTP status, 0 when no response was received.",
+	}, []string{"method", "code"})
+)
+
+// QueueCounter counts the articles waiting to be posted.
+type QueueCounter interface {
+	CountQueued(ctx context.Context) (int64, error)
+}
+
+type queueCollector struct {
+	queue QueueCounter
+	depth *prometheus.Desc
+}
+
+// RegisterQueue exposes the number of unposted articles, counted on every scrape.
+func RegisterQueue(queue QueueCounter) {
+	prometheus.MustRegister(&queueCollector{
+		queue: queue,
+		depth: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "queue_depth"),
+			"Articles waiting to be posted.",
+			nil, nil,
+		),
+	})
+}
+
+func (c *queueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.depth
+}
+
+func (c *queueCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), queueTimeout)
+	defer cancel()
+
+	depth, err := c.queue.CountQueued(ctx)
+	if err != nil {
+		log.Printf("[ERROR] Failed to count queued articles: %v", err)
+		ch <- prometheus.NewInvalidMetric(c.depth, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.depth, prometheus.GaugeValue, float64(depth))
+}
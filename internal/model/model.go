@@ -2,6 +2,44 @@ package model
 
 import "time"
 
+const (
+	ArticleStatusNew           = "new"
+	ArticleStatusPendingReview = "pending_review"
+	ArticleStatusApproved      = "approved"
+	ArticleStatusRejected      = "rejected"
+)
+
+// Publish states of an article: queued → selected → summarized → sending → posted or failed. A failed
+// article is retried until it runs out of attempts and becomes dead.
+const (
+	PublishQueued     = "queued"
+	PublishSelected   = "selected"
+	PublishSummarized = "summarized"
+	PublishSending    = "sending"
+	PublishPosted     = "posted"
+	PublishFailed     = "failed"
+	PublishDead       = "dead"
+)
+
+// Delivery states of an article post. An unconfirmed delivery was interrupted mid-send, so the
+// message may or may not be in the chat.
+const (
+	DeliverySending     = "sending"
+	DeliveryPosted      = "posted"
+	DeliveryUnconfirmed = "unconfirmed"
+)
+
+// Cross-post states: pending → sending → posted or failed. A failed cross-post is retried until it runs
+// out of attempts and becomes dead; an unconfirmed one was interrupted mid-send and is not retried.
+const (
+	CrosspostPending     = "pending"
+	CrosspostSending     = "sending"
+	CrosspostPosted      = "posted"
+	CrosspostFailed      = "failed"
+	CrosspostDead        = "dead"
+	CrosspostUnconfirmed = "unconfirmed"
+)
+
 type RSSArticle struct {
 	Title      string
 	Categories []string
@@ -12,27 +50,133 @@ type RSSArticle struct {
 }
 
 type Source struct {
+	ID            int64     `json:"id"`
+	Name          string    `json:"name"`
+	FeedURL       string    `json:"feedURL"`
+	TopicID       int64     `json:"topicID"`
+	Type          string    `json:"type"`
+	Priority      float64   `json:"priority"`
+	LastFetchedAt time.Time `json:"lastFetchedAt"`
+	LastError     string    `json:"lastError"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type Article struct {
+	ID               int64     `json:"id"`
+	SourceID         int64     `json:"sourceID"`
+	Title            string    `json:"title"`
+	Link             string    `json:"link"`
+	Summary          string    `json:"summary"`
+	Status           string    `json:"status"`
+	GeneratedSummary string    `json:"generatedSummary"`
+	EditedSummary    string    `json:"editedSummary"`
+	SummaryModel     string    `json:"summaryModel"`
+	SummaryPrompt    string    `json:"summaryPrompt"`
+	ReviewMessageID  int       `json:"reviewMessageID"`
+	PublishState     string    `json:"publishState"`
+	PublishError     string    `json:"publishError"`
+	PublishAttempts  int       `json:"publishAttempts"`
+	NextAttemptAt    time.Time `json:"nextAttemptAt"`
+	PublishedAt      time.Time `json:"publishedAt"`
+	CreatedAt        time.Time `json:"createdAt"`
+	PostedAt         time.Time `json:"postedAt"`
+}
+
+// PostSummary returns the summary to publish: the admin's edit if there is one, otherwise the generated one.
+func (a Article) PostSummary() string {
+	if a.EditedSummary != "" {
+		return a.EditedSummary
+	}
+
+	return a.GeneratedSummary
+}
+
+type Topic struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// PostedRetentionDays and UnpostedRetentionDays override the configured retention, zero keeps it.
+	PostedRetentionDays   int       `json:"postedRetentionDays"`
+	UnpostedRetentionDays int       `json:"unpostedRetentionDays"`
+	CreatedAt             time.Time `json:"createdAt"`
+}
+
+// RetentionReport tells how many articles of the topic are past their retention.
+type RetentionReport struct {
+	TopicID       int64
+	TopicName     string
+	PostedDays    int
+	UnpostedDays  int
+	ExpiredPosted int64
+	ExpiredStale  int64
+}
+
+type Channel struct {
+	ID              int64
+	ChatID          int64
+	Name            string
+	Timezone        string
+	Schedule        string
+	QuietHours      string
+	MaxPostsPerHour int
+	DigestMode      string
+	DigestTime      string
+	DigestWeekday   time.Weekday
+	DigestSize      int
+	DigestNarrative bool
+	LastDigestAt    time.Time
+	CreatedAt       time.Time
+}
+
+// ArticlePost is a delivery of an article to a chat. ChannelID is zero for the default channel.
+type ArticlePost struct {
+	ID        int64     `json:"id"`
+	ArticleID int64     `json:"articleID"`
+	ChannelID int64     `json:"channelID"`
+	ChatID    int64     `json:"chatID"`
+	MessageID int       `json:"messageID"`
+	Summary   string    `json:"summary"`
+	State     string    `json:"state"`
+	Likes     int       `json:"likes"`
+	Dislikes  int       `json:"dislikes"`
+	PostedAt  time.Time `json:"postedAt"`
+}
+
+type Filter struct {
+	ID        int64     `json:"id"`
+	Keyword   string    `json:"keyword"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Destination is a non-Telegram chat or network the articles of a topic are cross-posted to. Token, Room
+// and Account are only used by the destination types that need them.
+type Destination struct {
 	ID        int64
-	Name      string
-	FeedURL   string
 	TopicID   int64
 	Type      string
+	URL       string
+	Token     string
+	Room      string
+	Account   string
 	CreatedAt time.Time
 }
 
-type Article struct {
-	ID          int64
-	SourceID    int64
-	Title       string
-	Link        string
-	Summary     string
-	PublishedAt time.Time
-	CreatedAt   time.Time
+// Crosspost is the delivery of an article to a destination, sent by the notifier independently of
+// the Telegram posts.
+type Crosspost struct {
+	DestinationID int64
+	ArticleID     int64
+	Summary       string
+	State         string
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	PostedAt      time.Time
 }
 
-type Topic struct {
-	ID          int64
-	Name        string
-	Description string
-	CreatedAt   time.Time
+// Summary is a text generated by an AI client together with what produced it.
+type Summary struct {
+	Text   string
+	Model  string
+	Prompt string
 }
@@ -0,0 +1,119 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/samber/lo"
+	"log"
+	"tg-bot/internal/config"
+	"tg-bot/internal/metrics"
+	"tg-bot/internal/model"
+	"tg-bot/internal/publisher"
+	"time"
+)
+
+const crosspostBatchSize int64 = 50
+
+// queueCrossposts queues a delivered article for the other destinations of its topic, once per
+// destination. The cross-posts are sent by sendCrossposts, so destinations don't hold up Telegram
+// publishing and a failed cross-post is retried on its own.
+func (n *Notifier) queueCrossposts(ctx context.Context, article model.Article, postText string) error {
+	source, err := n.sources.SourceById(ctx, article.SourceID)
+	if err != nil {
+		return err
+	}
+
+	destinations, err := n.destinations.DestinationsByTopicId(ctx, source.TopicID)
+	if err != nil {
+		return err
+	}
+
+	for _, destination := range destinations {
+		if err := n.destinations.QueueCrosspost(ctx, model.Crosspost{
+			DestinationID: destination.ID,
+			ArticleID:     article.ID,
+			Summary:       postText,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sendCrossposts sends the queued cross-posts and retries the failed ones whose backoff has passed.
+func (n *Notifier) sendCrossposts(ctx context.Context) error {
+	crossposts, err := n.destinations.DueCrossposts(ctx, time.Now(), crosspostBatchSize)
+	if err != nil || len(crossposts) == 0 {
+		return err
+	}
+
+	destinations, err := n.destinations.Destinations(ctx)
+	if err != nil {
+		return err
+	}
+
+	byId := lo.KeyBy(destinations, func(destination model.Destination) int64 {
+		return destination.ID
+	})
+
+	var errs []error
+
+	for _, crosspost := range crossposts {
+		if err := n.sendCrosspost(ctx, byId[crosspost.DestinationID], crosspost); err != nil {
+			errs = append(errs, fmt.Errorf("cross-post of article %d to destination %d: %w",
+				crosspost.ArticleID, crosspost.DestinationID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// sendCrosspost publishes the article to the destination. The cross-post is marked sending first, so
+// one interrupted by a restart is left unconfirmed instead of being sent twice.
+func (n *Notifier) sendCrosspost(ctx context.Context, destination model.Destination, crosspost model.Crosspost) error {
+	article, err := n.articles.ArticleById(ctx, crosspost.ArticleID)
+	if err != nil {
+		return err
+	}
+
+	if err := n.destinations.SetCrosspostState(ctx, crosspost.DestinationID, crosspost.ArticleID,
+		model.CrosspostSending); err != nil {
+		return err
+	}
+
+	if err := publishTo(ctx, destination, publisher.NewPost(*article, crosspost.Summary)); err != nil {
+		return n.recordCrosspostFailure(ctx, crosspost, err)
+	}
+
+	metrics.PostsSent.WithLabelValues(destination.Type).Inc()
+
+	return n.destinations.ConfirmCrosspost(ctx, crosspost.DestinationID, crosspost.ArticleID)
+}
+
+func publishTo(ctx context.Context, destination model.Destination, post publisher.Post) error {
+	destinationPublisher, err := publisher.New(destination)
+	if err != nil {
+		return err
+	}
+
+	return destinationPublisher.Publish(ctx, post)
+}
+
+// recordCrosspostFailure counts a failed attempt with the same backoff and attempt limit as Telegram
+// posts. The cause is logged; only a failure to record it is returned.
+func (n *Notifier) recordCrosspostFailure(ctx context.Context, crosspost model.Crosspost, cause error) error {
+	attempts := crosspost.Attempts + 1
+
+	state := model.CrosspostFailed
+	if attempts >= config.Get().PublishMaxAttempts {
+		state = model.CrosspostDead
+	}
+
+	log.Printf("[ERROR] Failed to cross-post article %d to destination %d, attempt %d, %s: %v",
+		crosspost.ArticleID, crosspost.DestinationID, attempts, state, cause)
+
+	return n.destinations.RecordCrosspostFailure(ctx, crosspost.DestinationID, crosspost.ArticleID, state,
+		cause.Error(), time.Now().Add(retryDelay(attempts)))
+}
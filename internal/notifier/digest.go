@@ -0,0 +1,442 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/samber/lo"
+	"log"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"tg-bot/internal/botkit/markup"
+	"tg-bot/internal/config"
+	"tg-bot/internal/metrics"
+	"tg-bot/internal/model"
+	"tg-bot/internal/ranking"
+	"tg-bot/internal/schedule"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	narrativeReportLength = 2000
+	// digestTextLength caps the generated text of one digest entry.
+	digestTextLength = 1000
+	// telegramMessageLength is the longest message Telegram accepts, counted in UTF-16 code units.
+	telegramMessageLength = 4096
+)
+
+// pendingDigest keeps the entries generated for the pending digest of a channel, so that a digest
+// that could not be sent is retried without asking the AI client again for the same stories.
+type pendingDigest struct {
+	lastDigestAt time.Time
+	entries      map[string]digestEntry
+}
+
+// digestEntry is the text of one story in a digest and the articles it was written from.
+type digestEntry struct {
+	text     string
+	articles []model.Article
+}
+
+// articleFailure is an article left out of a digest story and why.
+type articleFailure struct {
+	article model.Article
+	cause   error
+}
+
+// sendDigests posts one digest message to every channel whose digest slot has come. A failing channel
+// does not keep the others from getting their digests.
+func (n *Notifier) sendDigests(ctx context.Context, sources []model.Source) error {
+	channels, err := n.channels.Channels(ctx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		now  = time.Now()
+		errs []error
+	)
+
+	for _, channel := range channels {
+		if err := n.sendDueDigest(ctx, channel, sources, now); err != nil {
+			errs = append(errs, fmt.Errorf("digest of channel %d: %w", channel.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) sendDueDigest(
+	ctx context.Context,
+	channel model.Channel,
+	sources []model.Source,
+	now time.Time,
+) error {
+	due, err := schedule.DigestDue(channel, config.Get().Timezone, now)
+	if err != nil || !due {
+		return err
+	}
+
+	// The slot stays open until a digest goes out, so articles of a slot whose stories all failed are
+	// sent once they are retried.
+	sent, err := n.sendDigest(ctx, channel, sources, now)
+	if err != nil || !sent {
+		return err
+	}
+
+	if err := n.channels.SetLastDigestAt(ctx, channel.ID, now); err != nil {
+		return err
+	}
+
+	delete(n.pendingDigests, channel.ID)
+
+	return nil
+}
+
+// sendDigest reports whether a digest message was sent.
+func (n *Notifier) sendDigest(
+	ctx context.Context,
+	channel model.Channel,
+	sources []model.Source,
+	now time.Time,
+) (bool, error) {
+	ranked, err := n.digestArticles(ctx, channel, sources, now)
+	if err != nil || len(ranked) == 0 {
+		return false, err
+	}
+
+	stories := lo.Chunk(ranked, 1)
+	if channel.DigestNarrative {
+		stories = clusterByTopic(ranked, sources)
+	}
+
+	stories = stories[:min(len(stories), channel.DigestSize)]
+
+	var (
+		text     = fmt.Sprintf("📰 *%s*", markup.EscapeForMarkdown(digestTitle(channel)))
+		included []model.Article
+		number   int
+	)
+
+	for _, story := range stories {
+		// Failed articles are left out and retried with backoff like failed posts, in a later digest.
+		entry, failures := n.pendingDigestEntry(ctx, channel, story)
+		if err := n.storeFailures(ctx, failures); err != nil {
+			return false, err
+		}
+
+		if len(entry.articles) == 0 {
+			continue
+		}
+
+		next := fmt.Sprintf("%s\n\n%d\\. %s", text, number+1, entry.text)
+
+		// Stories that don't fit into one message are left out like those beyond the digest size.
+		if messageLength(next) > telegramMessageLength {
+			continue
+		}
+
+		text = next
+		included = append(included, entry.articles...)
+		number++
+	}
+
+	if number == 0 {
+		return false, nil
+	}
+
+	msg := tgbotapi.NewMessage(channel.ChatID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdownV2
+	msg.DisableWebPagePreview = true
+
+	sent, err := n.sender.Send(msg)
+	if err != nil {
+		return false, err
+	}
+
+	metrics.PostsSent.WithLabelValues(telegramTarget).Inc()
+
+	return true, n.markDigestDelivered(ctx, channel, sent.MessageID, included, sources)
+}
+
+// pendingDigestEntry returns the entry of the story, generating it only once per digest slot of the channel,
+// and the articles that failed to make it into the entry.
+func (n *Notifier) pendingDigestEntry(
+	ctx context.Context,
+	channel model.Channel,
+	story []model.Article,
+) (digestEntry, []articleFailure) {
+	pending, ok := n.pendingDigests[channel.ID]
+	if !ok || !pending.lastDigestAt.Equal(channel.LastDigestAt) {
+		pending = pendingDigest{lastDigestAt: channel.LastDigestAt, entries: make(map[string]digestEntry)}
+		n.pendingDigests[channel.ID] = pending
+	}
+
+	key := storyKey(story)
+	if entry, ok := pending.entries[key]; ok {
+		return entry, nil
+	}
+
+	entry, failures := n.digestEntry(ctx, story)
+	if len(entry.articles) > 0 {
+		pending.entries[key] = entry
+	}
+
+	return entry, failures
+}
+
+func (n *Notifier) storeFailures(ctx context.Context, failures []articleFailure) error {
+	errs := make([]error, 0, len(failures))
+
+	for _, failure := range failures {
+		errs = append(errs, n.storeFailure(ctx, failure.article, failure.cause))
+	}
+
+	return errors.Join(errs...)
+}
+
+func storyFailures(story []model.Article, cause error) []articleFailure {
+	return lo.Map(story, func(article model.Article, _ int) articleFailure {
+		return articleFailure{article: article, cause: cause}
+	})
+}
+
+// digestArticles returns the ranked articles of the channel topics published since the last digest,
+// together with the failed ones left out of earlier digests.
+func (n *Notifier) digestArticles(
+	ctx context.Context,
+	channel model.Channel,
+	sources []model.Source,
+	now time.Time,
+) ([]model.Article, error) {
+	topicIds, err := n.channels.TopicIdsByChannelId(ctx, channel.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	since := channel.LastDigestAt
+	if since.IsZero() {
+		since = now.Add(-n.lookupTimeWindow)
+	}
+
+	var topicArticles []model.Article
+
+	for _, topicId := range topicIds {
+		articles, err := n.articles.NotPostedByTopicId(ctx, topicId, since, topicArticlesLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		topicArticles = append(topicArticles, articles...)
+	}
+
+	failed, err := n.articles.ArticlesByPublishState(ctx, model.PublishFailed)
+	if err != nil {
+		return nil, err
+	}
+
+	topicIdsBySource := lo.SliceToMap(sources, func(source model.Source) (int64, int64) {
+		return source.ID, source.TopicID
+	})
+
+	topicArticles = append(topicArticles, lo.Filter(failed, func(article model.Article, _ int) bool {
+		return article.PublishedAt.Before(since) && slices.Contains(topicIds, topicIdsBySource[article.SourceID])
+	})...)
+
+	candidates := lo.Filter(topicArticles, func(article model.Article, _ int) bool {
+		return n.publishable(article) && retryDue(article, now)
+	})
+
+	delivered, err := n.articles.DeliveredArticleIds(ctx, channel.ID, lo.Map(candidates,
+		func(article model.Article, _ int) int64 {
+			return article.ID
+		}))
+	if err != nil {
+		return nil, err
+	}
+
+	candidates = lo.Reject(candidates, func(article model.Article, _ int) bool {
+		return slices.Contains(delivered, article.ID)
+	})
+
+	return n.ranker.Rank(candidates, topicArticles, sources), nil
+}
+
+// digestEntry writes the entry of the story. Articles that fail are returned instead of an error, so
+// that the rest of the digest still goes out.
+func (n *Notifier) digestEntry(ctx context.Context, story []model.Article) (digestEntry, []articleFailure) {
+	if len(story) == 1 {
+		text, err := n.digestLine(ctx, story[0])
+		if err != nil {
+			return digestEntry{}, storyFailures(story, err)
+		}
+
+		return digestEntry{text: text, articles: story}, nil
+	}
+
+	return n.digestNarrative(ctx, story)
+}
+
+func (n *Notifier) digestLine(ctx context.Context, article model.Article) (string, error) {
+	text, err := n.extractText(ctx, article)
+	if err != nil {
+		return "", err
+	}
+
+	summary, err := n.openAIClient.Request(ctx, text, config.Get().AIDigestPrompt)
+	if err != nil {
+		return "", err
+	}
+
+	line := "*" + markup.EscapeForMarkdown(article.Title) + "*"
+	if summary = strings.TrimSpace(summary); summary != "" {
+		line += "\n" + markup.EscapeForMarkdown(truncate(summary, digestTextLength))
+	}
+
+	return line + "\n" + markup.EscapeForMarkdown(article.Link), nil
+}
+
+// digestNarrative asks the AI client for one account of a story covered by several articles. Articles
+// whose text can't be read are left out of the story.
+func (n *Notifier) digestNarrative(ctx context.Context, story []model.Article) (digestEntry, []articleFailure) {
+	var (
+		reports  = make([]string, 0, len(story))
+		articles = make([]model.Article, 0, len(story))
+		failures []articleFailure
+	)
+
+	for _, article := range story {
+		text, err := n.extractText(ctx, article)
+		if err != nil {
+			failures = append(failures, articleFailure{article: article, cause: err})
+			continue
+		}
+
+		reports = append(reports, article.Title+"\n"+truncate(text, narrativeReportLength))
+		articles = append(articles, article)
+	}
+
+	if len(articles) == 0 {
+		return digestEntry{}, failures
+	}
+
+	narrative, err := n.openAIClient.Request(ctx, strings.Join(reports, "\n\n"), config.Get().AIClusterPrompt)
+	if err != nil {
+		return digestEntry{}, append(failures, storyFailures(articles, err)...)
+	}
+
+	text := "*" + markup.EscapeForMarkdown(articles[0].Title) + "*"
+	if narrative = strings.TrimSpace(narrative); narrative != "" {
+		text += "\n" + markup.EscapeForMarkdown(truncate(narrative, digestTextLength))
+	}
+
+	links := lo.Map(articles, func(article model.Article, _ int) string {
+		return markup.EscapeForMarkdown(article.Link)
+	})
+
+	return digestEntry{text: text + "\nSources: " + strings.Join(links, "\n"), articles: articles}, failures
+}
+
+func (n *Notifier) markDigestDelivered(
+	ctx context.Context,
+	channel model.Channel,
+	messageId int,
+	articles []model.Article,
+	sources []model.Source,
+) error {
+	topicChannels := make(map[int64][]model.Channel)
+
+	for _, article := range articles {
+		if err := n.articles.MarkDelivered(ctx, model.ArticlePost{
+			ArticleID: article.ID,
+			ChannelID: channel.ID,
+			ChatID:    channel.ChatID,
+			MessageID: messageId,
+		}); err != nil {
+			return err
+		}
+
+		if err := n.queueCrossposts(ctx, article, article.PostSummary()); err != nil {
+			log.Printf("[ERROR] Failed to queue cross-posts of article %d: %v", article.ID, err)
+		}
+
+		source, _ := lo.Find(sources, func(source model.Source) bool {
+			return source.ID == article.SourceID
+		})
+
+		if _, ok := topicChannels[source.TopicID]; !ok {
+			channels, err := n.channels.ChannelsByTopicId(ctx, source.TopicID)
+			if err != nil {
+				return err
+			}
+
+			topicChannels[source.TopicID] = channels
+		}
+
+		if err := n.markPostedIfDelivered(ctx, article.ID, topicChannels[source.TopicID]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// clusterByTopic merges related stories within each topic and orders clusters by their best ranked article.
+func clusterByTopic(ranked []model.Article, sources []model.Source) [][]model.Article {
+	var (
+		topicBySource = lo.SliceToMap(sources, func(source model.Source) (int64, int64) {
+			return source.ID, source.TopicID
+		})
+		byTopic = lo.GroupBy(ranked, func(article model.Article) int64 {
+			return topicBySource[article.SourceID]
+		})
+		position = make(map[int64]int, len(ranked))
+		clusters [][]model.Article
+	)
+
+	for i, article := range ranked {
+		position[article.ID] = i
+	}
+
+	for _, articles := range byTopic {
+		clusters = append(clusters, ranking.Cluster(articles, ranking.SameStoryThreshold)...)
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		return position[clusters[i][0].ID] < position[clusters[j][0].ID]
+	})
+
+	return clusters
+}
+
+// storyKey identifies a story by its articles.
+func storyKey(story []model.Article) string {
+	return strings.Join(lo.Map(story, func(article model.Article, _ int) string {
+		return strconv.FormatInt(article.ID, 10)
+	}), ",")
+}
+
+func messageLength(text string) int {
+	return len(utf16.Encode([]rune(text)))
+}
+
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+
+	return string(runes[:limit])
+}
+
+func digestTitle(channel model.Channel) string {
+	if channel.DigestMode == schedule.DigestWeekly {
+		return "Weekly digest"
+	}
+
+	return "Daily digest"
+}
@@ -2,6 +2,7 @@ package notifier
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/go-shiori/go-readability"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -12,128 +13,274 @@ import (
 	"regexp"
 	"slices"
 	"strings"
-	"tg-bot/internal/botkit/markup"
 	"tg-bot/internal/config"
+	"tg-bot/internal/health"
+	"tg-bot/internal/metrics"
 	"tg-bot/internal/model"
+	"tg-bot/internal/publisher"
+	"tg-bot/internal/schedule"
 	"time"
 )
 
 var (
 	NewLinesRegexp = regexp.MustCompile(`\n{3,}`)
+
+	pageClient = &http.Client{Timeout: pageTimeout}
 )
 
 const (
-	articlesOffset int64  = 1000
-	translation    string = "translation"
+	topicArticlesLimit int64  = 200
+	pageTimeout               = 30 * time.Second
+	translation        string = "translation"
+	// telegramTarget labels the posts sent to Telegram in the metrics, next to the destination types.
+	telegramTarget string = "telegram"
 )
 
 type ArticleProvider interface {
-	FindAllNotPosted(ctx context.Context, since time.Time, limit int64) ([]model.Article, error)
+	NotPostedByTopicId(ctx context.Context, topicId int64, since time.Time, limit int64) ([]model.Article, error)
+	ReviewedByTopicId(ctx context.Context, topicId int64) ([]model.Article, error)
 	MarkPostedById(ctx context.Context, id int64) error
+	MarkDelivered(ctx context.Context, post model.ArticlePost) error
+	DeliveredArticleIds(ctx context.Context, channelId int64, articleIds []int64) ([]int64, error)
+	DeliveredChannelIds(ctx context.Context, articleId int64) ([]int64, error)
+	CountDeliveredSince(ctx context.Context, channelId int64, since time.Time) (int, error)
+	MarkPendingReview(ctx context.Context, id int64, messageId int) error
+	SetGeneratedSummary(ctx context.Context, id int64, summary model.Summary) error
+	ArticleById(ctx context.Context, id int64) (*model.Article, error)
+	SetStatus(ctx context.Context, id int64, status string) error
+	PostsByArticleId(ctx context.Context, articleId int64) ([]model.ArticlePost, error)
+	CountPostsByMessage(ctx context.Context, chatId int64, messageId int) (int, error)
+	DeletePost(ctx context.Context, id int64) error
+	ResetPosted(ctx context.Context, id int64) error
+	ClaimDelivery(ctx context.Context, post model.ArticlePost) (int64, bool, error)
+	ConfirmDelivery(ctx context.Context, id int64, messageId int) error
+	CancelDelivery(ctx context.Context, id int64) error
+	UnconfirmDeliveries(ctx context.Context) (int64, error)
+	SetPublishState(ctx context.Context, id int64, state string, publishError string) error
+	ReplacePublishState(ctx context.Context, from string, to string) error
+	ArticlesByPublishState(ctx context.Context, state string) ([]model.Article, error)
+	RecordPublishFailure(ctx context.Context, id int64, state string, publishError string, nextAttemptAt time.Time) error
 }
 
 type SourceProvider interface {
 	SourcesByTopicId(ctx context.Context, topicId int64) ([]model.Source, error)
 	Sources(ctx context.Context) ([]model.Source, error)
+	SourceById(ctx context.Context, id int64) (*model.Source, error)
+}
+
+type ChannelProvider interface {
+	Channels(ctx context.Context) ([]model.Channel, error)
+	ChannelsByTopicId(ctx context.Context, topicId int64) ([]model.Channel, error)
+	TopicIdsByChannelId(ctx context.Context, channelId int64) ([]int64, error)
+	SetLastDigestAt(ctx context.Context, id int64, at time.Time) error
+}
+
+type DestinationProvider interface {
+	Destinations(ctx context.Context) ([]model.Destination, error)
+	DestinationsByTopicId(ctx context.Context, topicId int64) ([]model.Destination, error)
+	QueueCrosspost(ctx context.Context, crosspost model.Crosspost) error
+	ForgetCrossposts(ctx context.Context, articleId int64) error
+	DueCrossposts(ctx context.Context, now time.Time, limit int64) ([]model.Crosspost, error)
+	SetCrosspostState(ctx context.Context, destinationId int64, articleId int64, state string) error
+	ConfirmCrosspost(ctx context.Context, destinationId int64, articleId int64) error
+	RecordCrosspostFailure(
+		ctx context.Context,
+		destinationId int64,
+		articleId int64,
+		state string,
+		crosspostError string,
+		nextAttemptAt time.Time,
+	) error
+	UnconfirmCrossposts(ctx context.Context) (int64, error)
+}
+
+type Ranker interface {
+	Rank(candidates []model.Article, corpus []model.Article, sources []model.Source) []model.Article
+}
+
+// Sender delivers messages to Telegram chats; *tgbotapi.BotAPI is one. Posts, digests, review previews
+// and deletes of unposted messages all go through it.
+type Sender interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
 }
 
 type AIClient interface {
 	Request(ctx context.Context, text string, prompt string) (string, error)
+	Model() string
 }
 
 type Notifier struct {
 	articles         ArticleProvider
 	sources          SourceProvider
+	channels         ChannelProvider
+	destinations     DestinationProvider
+	ranker           Ranker
 	openAIClient     AIClient
-	bot              *tgbotapi.BotAPI
+	sender           Sender
 	sendInterval     time.Duration
 	lookupTimeWindow time.Duration
 	channelId        int64
+	reviewChatId     int64
+	heartbeat        health.Heartbeat
+	pendingDigests   map[int64]pendingDigest
+	// trigger holds a run requested between ticks, see Trigger.
+	trigger chan struct{}
 }
 
 func NewNotifier(
 	articles ArticleProvider,
 	sources SourceProvider,
+	channels ChannelProvider,
+	destinations DestinationProvider,
+	ranker Ranker,
 	summarizer AIClient,
-	bot *tgbotapi.BotAPI,
+	sender Sender,
 	sendInterval time.Duration,
 	lookupTimeWindow time.Duration,
 	channelId int64,
+	reviewChatId int64,
 ) *Notifier {
 	return &Notifier{
 		articles:         articles,
 		sources:          sources,
+		channels:         channels,
+		destinations:     destinations,
+		ranker:           ranker,
 		openAIClient:     summarizer,
-		bot:              bot,
+		sender:           sender,
 		sendInterval:     sendInterval,
 		lookupTimeWindow: lookupTimeWindow,
 		channelId:        channelId,
+		reviewChatId:     reviewChatId,
+		pendingDigests:   make(map[int64]pendingDigest),
+		trigger:          make(chan struct{}, 1),
 	}
 }
 
+// Start publishes articles every send interval. Failures are logged and do not stop the loop: failed
+// articles are retried with backoff while the other topics keep being delivered.
 func (n *Notifier) Start(ctx context.Context) error {
 	ticker := time.NewTicker(n.sendInterval)
 	defer ticker.Stop()
 
+	if err := n.Recover(ctx); err != nil {
+		log.Printf("[ERROR] Failed to recover interrupted publishing: %v", err)
+	}
+
+	n.heartbeat.Beat()
+
 	if err := n.SelectAndSendArticle(ctx); err != nil {
-		return err
+		log.Printf("[ERROR] Failed to send articles: %v", err)
 	}
 
+	n.heartbeat.Beat()
+
 	for {
 		select {
 		case <-ticker.C:
 			if err := n.SelectAndSendArticle(ctx); err != nil {
-				return err
+				log.Printf("[ERROR] Failed to send articles: %v", err)
+			}
+
+			n.heartbeat.Beat()
+		case <-n.trigger:
+			if err := n.SelectAndSendArticle(ctx); err != nil {
+				log.Printf("[ERROR] Failed to send articles: %v", err)
 			}
+
+			n.heartbeat.Beat()
 		case <-ctx.Done():
 			return ctx.Err()
 		}
 	}
 }
 
-func (n *Notifier) SelectAndSendArticle(ctx context.Context) error {
-	topArticles, err := n.articles.FindAllNotPosted(ctx, time.Now().Add(-n.lookupTimeWindow), articlesOffset)
-	if err != nil {
-		return err
+// Trigger asks the notifier loop to send right after the current run, if any, instead of waiting for
+// the next tick. Runs never overlap, since only the loop sends. It reports false if a run is already waiting.
+func (n *Notifier) Trigger() bool {
+	select {
+	case n.trigger <- struct{}{}:
+		return true
+	default:
+		return false
 	}
+}
 
-	if len(topArticles) == 0 {
-		return nil
-	}
+// LastTick returns when the notifier loop last started or finished sending.
+func (n *Notifier) LastTick() time.Time {
+	return n.heartbeat.Last()
+}
 
+// SelectAndSendArticle delivers the best queued article of every topic. A failing topic does not keep
+// the others from being delivered; all failures are returned together.
+func (n *Notifier) SelectAndSendArticle(ctx context.Context) error {
 	sources, err := n.sources.Sources(ctx)
 	if err != nil {
 		return err
 	}
 
+	var (
+		summaries = make(map[int64]string)
+		errs      []error
+	)
+
 	for _, topicId := range getUniqueTopicIds(sources) {
+		if err := n.sendTopic(ctx, topicId, sources, summaries); err != nil {
+			errs = append(errs, fmt.Errorf("topic %d: %w", topicId, err))
+		}
+	}
 
-		sourcesForTopicId := lo.Filter(sources, func(source model.Source, _ int) bool {
-			return source.TopicID == topicId
-		})
+	if err := n.sendDigests(ctx, sources); err != nil {
+		errs = append(errs, err)
+	}
 
-		sourceIds := lo.Map(sourcesForTopicId, func(source model.Source, _ int) int64 {
-			return source.ID
-		})
+	if err := n.sendCrossposts(ctx); err != nil {
+		errs = append(errs, err)
+	}
 
-		article := lo.Filter(topArticles, func(article model.Article, _ int) bool {
-			return slices.Contains(sourceIds, article.SourceID)
-		})[0]
+	return errors.Join(errs...)
+}
 
-		postSource, _ := lo.Find(sourcesForTopicId, func(source model.Source) bool {
-			return source.ID == article.SourceID
-		})
+// sendTopic delivers the best queued article of the topic. Every topic gets its own batch of
+// candidates, so a busy topic can't crowd the others out, and a topic with nothing queued is skipped.
+func (n *Notifier) sendTopic(
+	ctx context.Context,
+	topicId int64,
+	sources []model.Source,
+	summaries map[int64]string,
+) error {
+	candidates, sourcesForTopicId, err := n.rankedCandidates(ctx, topicId, sources)
+	if err != nil || len(candidates) == 0 {
+		return err
+	}
 
-		postText, err := n.extractSummary(ctx, article, postSource.Type)
-		if err != nil {
+	if n.reviewChatId != 0 {
+		if err := n.sendForReview(ctx, candidates, sourcesForTopicId); err != nil {
 			return err
 		}
+	}
 
-		if err := n.sendArticle(postText, article); err != nil {
-			return err
+	candidates = lo.Filter(candidates, func(article model.Article, _ int) bool {
+		return n.publishable(article)
+	})
+
+	channels, err := n.channels.ChannelsByTopicId(ctx, topicId)
+	if err != nil {
+		return err
+	}
+
+	if len(channels) == 0 {
+		if len(candidates) == 0 {
+			return nil
 		}
 
-		if err := n.articles.MarkPostedById(ctx, article.ID); err != nil {
+		return n.sendToDefaultChannel(ctx, candidates[0], sourcesForTopicId, summaries)
+	}
+
+	for _, channel := range channels {
+		// The failed article stays first in line, so the remaining channels wait for its retry.
+		if err := n.sendToChannel(ctx, channel, channels, candidates, sourcesForTopicId, summaries); err != nil {
 			return err
 		}
 	}
@@ -141,6 +288,48 @@ func (n *Notifier) SelectAndSendArticle(ctx context.Context) error {
 	return nil
 }
 
+// rankedCandidates returns the queued articles of the topic that are due to be tried, best first,
+// together with the sources of the topic.
+func (n *Notifier) rankedCandidates(
+	ctx context.Context,
+	topicId int64,
+	sources []model.Source,
+) ([]model.Article, []model.Source, error) {
+	now := time.Now()
+
+	topicArticles, err := n.articles.NotPostedByTopicId(ctx, topicId, now.Add(-n.lookupTimeWindow), topicArticlesLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if n.reviewChatId != 0 {
+		// Articles under review stay in line however old they get: a pending one keeps the next one from
+		// being sent for review and an approved one is still published.
+		reviewed, err := n.articles.ReviewedByTopicId(ctx, topicId)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		topicArticles = lo.UniqBy(append(topicArticles, reviewed...), func(article model.Article) int64 {
+			return article.ID
+		})
+	}
+
+	if len(topicArticles) == 0 {
+		return nil, nil, nil
+	}
+
+	sourcesForTopicId := lo.Filter(sources, func(source model.Source, _ int) bool {
+		return source.TopicID == topicId
+	})
+
+	candidates := n.ranker.Rank(lo.Filter(topicArticles, func(article model.Article, _ int) bool {
+		return retryDue(article, now)
+	}), topicArticles, sourcesForTopicId)
+
+	return candidates, sourcesForTopicId, nil
+}
+
 func getUniqueTopicIds(sources []model.Source) []int64 {
 	topicIds := make([]int64, 0, len(sources))
 
@@ -151,13 +340,236 @@ func getUniqueTopicIds(sources []model.Source) []int64 {
 	return lo.Uniq(topicIds)
 }
 
-func (n *Notifier) extractSummary(ctx context.Context, article model.Article, postType string) (string, error) {
+// sendToDefaultChannel posts articles of topics that have no linked channels.
+func (n *Notifier) sendToDefaultChannel(
+	ctx context.Context,
+	article model.Article,
+	sources []model.Source,
+	summaries map[int64]string,
+) error {
+	postText, err := n.prepare(ctx, article, sources, summaries)
+	if err != nil {
+		return err
+	}
+
+	if err := n.publish(ctx, article, 0, n.channelId, postText); err != nil {
+		return err
+	}
+
+	return n.articles.MarkPostedById(ctx, article.ID)
+}
+
+// sendToChannel posts the highest ranked queued article of the topic if the channel schedule allows it.
+func (n *Notifier) sendToChannel(
+	ctx context.Context,
+	channel model.Channel,
+	topicChannels []model.Channel,
+	candidates []model.Article,
+	sources []model.Source,
+	summaries map[int64]string,
+) error {
+	if channel.DigestMode != schedule.DigestOff {
+		return nil
+	}
+
+	isOpen, err := n.channelIsOpen(ctx, channel)
+	if err != nil || !isOpen {
+		return err
+	}
+
+	delivered, err := n.articles.DeliveredArticleIds(ctx, channel.ID, lo.Map(candidates,
+		func(article model.Article, _ int) int64 {
+			return article.ID
+		}))
+	if err != nil {
+		return err
+	}
+
+	article, ok := lo.Find(candidates, func(article model.Article) bool {
+		return !slices.Contains(delivered, article.ID)
+	})
+	if !ok {
+		return nil
+	}
+
+	postText, err := n.prepare(ctx, article, sources, summaries)
+	if err != nil {
+		return err
+	}
+
+	if err := n.publish(ctx, article, channel.ID, channel.ChatID, postText); err != nil {
+		return err
+	}
+
+	return n.markPostedIfDelivered(ctx, article.ID, topicChannels)
+}
+
+// publish sends the article to the chat through the outbox: the delivery is claimed before the
+// message goes out and confirmed after, so a failed database update never causes a second post.
+// channelId is zero for the default channel.
+func (n *Notifier) publish(ctx context.Context, article model.Article, channelId int64, chatId int64, postText string) error {
+	postId, claimed, err := n.articles.ClaimDelivery(ctx, model.ArticlePost{
+		ArticleID: article.ID,
+		ChannelID: channelId,
+		ChatID:    chatId,
+		Summary:   postText,
+	})
+	if err != nil || !claimed {
+		return err
+	}
+
+	if err := n.articles.SetPublishState(ctx, article.ID, model.PublishSending, ""); err != nil {
+		return err
+	}
+
+	msg, err := n.sendArticle(chatId, postText, article)
+	if err != nil {
+		if cancelErr := n.articles.CancelDelivery(ctx, postId); cancelErr != nil {
+			return errors.Join(err, cancelErr)
+		}
+
+		return n.recordFailure(ctx, article, err)
+	}
+
+	metrics.PostsSent.WithLabelValues(telegramTarget).Inc()
+
+	if err := n.articles.ConfirmDelivery(ctx, postId, msg.MessageID); err != nil {
+		return err
+	}
+
+	if err := n.queueCrossposts(ctx, article, postText); err != nil {
+		log.Printf("[ERROR] Failed to queue cross-posts of article %d: %v", article.ID, err)
+	}
+
+	return nil
+}
+
+// markPostedIfDelivered marks the article posted once every channel of its topic has received it.
+func (n *Notifier) markPostedIfDelivered(ctx context.Context, articleId int64, topicChannels []model.Channel) error {
+	deliveredTo, err := n.articles.DeliveredChannelIds(ctx, articleId)
+	if err != nil {
+		return err
+	}
+
+	if !lo.Every(deliveredTo, lo.Map(topicChannels, func(channel model.Channel, _ int) int64 {
+		return channel.ID
+	})) {
+		return n.articles.SetPublishState(ctx, articleId, model.PublishSummarized, "")
+	}
+
+	return n.articles.MarkPostedById(ctx, articleId)
+}
+
+func (n *Notifier) channelIsOpen(ctx context.Context, channel model.Channel) (bool, error) {
+	channelSchedule, err := schedule.ForChannel(channel, config.Get().Timezone)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+
+	// The window may have matched any minute since the previous tick.
+	if !channelSchedule.IsOpen(now, now.Add(-n.sendInterval)) {
+		return false, nil
+	}
+
+	postedLastHour, err := n.articles.CountDeliveredSince(ctx, channel.ID, now.Add(-time.Hour))
+	if err != nil {
+		return false, err
+	}
+
+	return channelSchedule.AllowsMore(postedLastHour), nil
+}
+
+// prepare moves the chosen article through the selected and summarized publish states and returns its post text.
+func (n *Notifier) prepare(
+	ctx context.Context,
+	article model.Article,
+	sources []model.Source,
+	summaries map[int64]string,
+) (string, error) {
+	if article.PublishState != model.PublishSummarized {
+		if err := n.articles.SetPublishState(ctx, article.ID, model.PublishSelected, ""); err != nil {
+			return "", err
+		}
+	}
+
+	postText, err := n.summaryFor(ctx, article, sources, summaries)
+	if err != nil {
+		return "", n.recordFailure(ctx, article, err)
+	}
+
+	if err := n.articles.SetPublishState(ctx, article.ID, model.PublishSummarized, ""); err != nil {
+		return "", err
+	}
+
+	return postText, nil
+}
+
+// summaryFor returns the stored summary of the article, generating and storing it on first use.
+func (n *Notifier) summaryFor(
+	ctx context.Context,
+	article model.Article,
+	sources []model.Source,
+	summaries map[int64]string,
+) (string, error) {
+	if summary := article.PostSummary(); summary != "" {
+		return summary, nil
+	}
+
+	if postText, ok := summaries[article.ID]; ok {
+		return postText, nil
+	}
+
+	postSource, _ := lo.Find(sources, func(source model.Source) bool {
+		return source.ID == article.SourceID
+	})
+
+	summary, err := n.extractSummary(ctx, article, postSource.Type)
+	if err != nil {
+		return "", err
+	}
+
+	if err := n.articles.SetGeneratedSummary(ctx, article.ID, summary); err != nil {
+		return "", err
+	}
+
+	summaries[article.ID] = summary.Text
+
+	return summary.Text, nil
+}
+
+// GenerateSummary produces a fresh summary of the article with the prompt matching its source type.
+func (n *Notifier) GenerateSummary(ctx context.Context, article model.Article) (model.Summary, error) {
+	source, err := n.sources.SourceById(ctx, article.SourceID)
+	if err != nil {
+		return model.Summary{}, err
+	}
+
+	return n.extractSummary(ctx, article, source.Type)
+}
+
+func (n *Notifier) extractSummary(ctx context.Context, article model.Article, postType string) (model.Summary, error) {
+	text, err := n.extractText(ctx, article)
+	if err != nil {
+		return model.Summary{}, err
+	}
+
+	return n.makeSummary(ctx, text, postType)
+}
+
+func (n *Notifier) extractText(ctx context.Context, article model.Article) (string, error) {
 	var reader io.Reader
 
 	if article.Summary != "" {
 		reader = strings.NewReader(article.Summary)
 	} else {
-		response, err := http.Get(article.Link)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, article.Link, nil)
+		if err != nil {
+			return "", err
+		}
+
+		response, err := pageClient.Do(req)
 		if err != nil {
 			return "", err
 		}
@@ -168,6 +580,11 @@ func (n *Notifier) extractSummary(ctx context.Context, article model.Article, po
 			}
 		}(response.Body)
 
+		// An error page would otherwise be summarized and published as the article.
+		if response.StatusCode < 200 || response.StatusCode >= 300 {
+			return "", fmt.Errorf("GET %s: %s", article.Link, response.Status)
+		}
+
 		reader = response.Body
 	}
 
@@ -176,29 +593,33 @@ func (n *Notifier) extractSummary(ctx context.Context, article model.Article, po
 		return "", err
 	}
 
-	return n.makeSummary(ctx, doc, postType)
+	return cleanText(doc.TextContent), nil
 }
 
-func (n *Notifier) makeSummary(ctx context.Context, article readability.Article, postType string) (string, error) {
+func (n *Notifier) makeSummary(ctx context.Context, text string, postType string) (model.Summary, error) {
 	switch postType {
 	case translation:
-		translation, err := n.openAIClient.Request(ctx, cleanText(article.TextContent), config.Get().AITranslationPrompt)
+		prompt := config.Get().AITranslationPrompt
+
+		translation, err := n.openAIClient.Request(ctx, text, prompt)
 		if err != nil {
-			return "", err
+			return model.Summary{}, err
 		}
 
 		log.Println("Translation post")
 
-		return "\n\n" + translation, nil
+		return model.Summary{Text: translation, Model: n.openAIClient.Model(), Prompt: prompt}, nil
 	default:
-		summary, err := n.openAIClient.Request(ctx, cleanText(article.TextContent), config.Get().AIDefaultPrompt)
+		prompt := config.Get().AIDefaultPrompt
+
+		summary, err := n.openAIClient.Request(ctx, text, prompt)
 		if err != nil {
-			return "", err
+			return model.Summary{}, err
 		}
 
 		log.Println("Summary post")
 
-		return "\n\n" + summary, nil
+		return model.Summary{Text: summary, Model: n.openAIClient.Model(), Prompt: prompt}, nil
 	}
 }
 
@@ -206,21 +627,6 @@ func cleanText(text string) string {
 	return NewLinesRegexp.ReplaceAllString(text, "\n")
 }
 
-func (n *Notifier) sendArticle(summary string, article model.Article) error {
-	const msgFormat = "*%s*%s\n\n%s"
-
-	msg := tgbotapi.NewMessage(n.channelId, fmt.Sprintf(
-		msgFormat,
-		markup.EscapeForMarkdown(article.Title),
-		markup.EscapeForMarkdown(summary),
-		markup.EscapeForMarkdown(article.Link),
-	))
-	msg.ParseMode = tgbotapi.ModeMarkdownV2
-
-	_, err := n.bot.Send(msg)
-	if err != nil {
-		return err
-	}
-
-	return nil
+func (n *Notifier) sendArticle(chatId int64, summary string, article model.Article) (tgbotapi.Message, error) {
+	return n.sender.Send(publisher.NewTelegramMessage(chatId, publisher.NewPost(article, summary)))
 }
@@ -0,0 +1,268 @@
+package notifier
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"testing"
+	"tg-bot/internal/model"
+	"time"
+)
+
+// fakeArticles serves the unposted articles of each topic. The embedded interface is nil, so any call
+// the notifier is not expected to make panics.
+type fakeArticles struct {
+	ArticleProvider
+
+	byTopic  map[int64][]model.Article
+	reviewed map[int64][]model.Article
+	queried  map[int64]articleQuery
+}
+
+type articleQuery struct {
+	since time.Time
+	limit int64
+}
+
+func (f *fakeArticles) NotPostedByTopicId(
+	_ context.Context,
+	topicId int64,
+	since time.Time,
+	limit int64,
+) ([]model.Article, error) {
+	if f.queried == nil {
+		f.queried = make(map[int64]articleQuery)
+	}
+
+	f.queried[topicId] = articleQuery{since: since, limit: limit}
+
+	return f.byTopic[topicId], nil
+}
+
+func (f *fakeArticles) ReviewedByTopicId(_ context.Context, topicId int64) ([]model.Article, error) {
+	return f.reviewed[topicId], nil
+}
+
+type fakeSources struct {
+	SourceProvider
+
+	sources []model.Source
+}
+
+func (f *fakeSources) Sources(context.Context) ([]model.Source, error) {
+	return f.sources, nil
+}
+
+type fakeChannels struct {
+	ChannelProvider
+}
+
+func (f *fakeChannels) Channels(context.Context) ([]model.Channel, error) {
+	return nil, nil
+}
+
+func (f *fakeChannels) ChannelsByTopicId(context.Context, int64) ([]model.Channel, error) {
+	return nil, nil
+}
+
+type fakeDestinations struct {
+	DestinationProvider
+}
+
+func (f *fakeDestinations) DueCrossposts(context.Context, time.Time, int64) ([]model.Crosspost, error) {
+	return nil, nil
+}
+
+// fakeSender records the messages instead of sending them.
+type fakeSender struct {
+	Sender
+
+	sent []tgbotapi.Chattable
+}
+
+func (f *fakeSender) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	f.sent = append(f.sent, c)
+
+	return tgbotapi.Message{MessageID: len(f.sent)}, nil
+}
+
+type keepOrderRanker struct{}
+
+func (keepOrderRanker) Rank(candidates []model.Article, _ []model.Article, _ []model.Source) []model.Article {
+	return candidates
+}
+
+// recordingRanker keeps what it was asked to rank and returns the candidates in order.
+type recordingRanker struct {
+	corpus  []model.Article
+	sources []model.Source
+}
+
+func (r *recordingRanker) Rank(candidates []model.Article, corpus []model.Article, sources []model.Source) []model.Article {
+	r.corpus, r.sources = corpus, sources
+
+	return candidates
+}
+
+func newTestNotifier(articles *fakeArticles, sources []model.Source) *Notifier {
+	return newReviewTestNotifier(articles, sources, &fakeSender{}, 0)
+}
+
+func newReviewTestNotifier(articles *fakeArticles, sources []model.Source, sender Sender, reviewChatId int64) *Notifier {
+	return NewNotifier(
+		articles,
+		&fakeSources{sources: sources},
+		&fakeChannels{},
+		&fakeDestinations{},
+		keepOrderRanker{},
+		nil,
+		sender,
+		time.Minute,
+		time.Hour,
+		0,
+		reviewChatId,
+	)
+}
+
+func TestSelectAndSendArticleSkipsTopicsWithoutArticles(t *testing.T) {
+	articles := &fakeArticles{}
+	n := newTestNotifier(articles, []model.Source{
+		{ID: 1, TopicID: 1},
+		{ID: 2, TopicID: 2},
+	})
+
+	if err := n.SelectAndSendArticle(context.Background()); err != nil {
+		t.Fatalf("SelectAndSendArticle() error = %v", err)
+	}
+
+	for _, topicId := range []int64{1, 2} {
+		if _, ok := articles.queried[topicId]; !ok {
+			t.Errorf("topic %d was not queried", topicId)
+		}
+	}
+}
+
+func TestRankedCandidatesAreQueriedPerTopic(t *testing.T) {
+	articles := &fakeArticles{byTopic: map[int64][]model.Article{
+		2: {{ID: 1000, SourceID: 2}},
+	}}
+	sources := []model.Source{
+		{ID: 1, TopicID: 1},
+		{ID: 2, TopicID: 2},
+	}
+	n := newTestNotifier(articles, sources)
+
+	started := time.Now()
+
+	if _, _, err := n.rankedCandidates(context.Background(), 2, sources); err != nil {
+		t.Fatalf("rankedCandidates() error = %v", err)
+	}
+
+	query, ok := articles.queried[2]
+	if !ok || len(articles.queried) != 1 {
+		t.Fatalf("queried topics %v, want only topic 2", articles.queried)
+	}
+
+	// Every topic gets a window of its own, so a busy topic can't crowd the others out.
+	if query.limit != topicArticlesLimit {
+		t.Errorf("queried %d articles, want %d", query.limit, topicArticlesLimit)
+	}
+
+	if since := started.Add(-n.lookupTimeWindow); query.since.Before(since.Add(-time.Second)) || query.since.After(time.Now()) {
+		t.Errorf("queried articles since %v, want the lookup window since %v", query.since, since)
+	}
+}
+
+func TestRankedCandidatesSkipArticlesNotDue(t *testing.T) {
+	var (
+		now = time.Now()
+
+		queued     = model.Article{ID: 1, SourceID: 1, PublishState: model.PublishQueued}
+		dueRetry   = model.Article{ID: 2, SourceID: 1, PublishState: model.PublishFailed, NextAttemptAt: now.Add(-time.Minute)}
+		laterRetry = model.Article{ID: 3, SourceID: 1, PublishState: model.PublishFailed, NextAttemptAt: now.Add(time.Hour)}
+		dead       = model.Article{ID: 4, SourceID: 1, PublishState: model.PublishDead}
+
+		articles = &fakeArticles{byTopic: map[int64][]model.Article{
+			1: {queued, dueRetry, laterRetry, dead},
+		}}
+		sources = []model.Source{
+			{ID: 1, TopicID: 1},
+			{ID: 2, TopicID: 2},
+		}
+		ranker = &recordingRanker{}
+	)
+
+	n := newTestNotifier(articles, sources)
+	n.ranker = ranker
+
+	candidates, topicSources, err := n.rankedCandidates(context.Background(), 1, sources)
+	if err != nil {
+		t.Fatalf("rankedCandidates() error = %v", err)
+	}
+
+	if len(candidates) != 2 || candidates[0].ID != queued.ID || candidates[1].ID != dueRetry.ID {
+		t.Errorf("rankedCandidates() = %v, want articles %d and %d", candidates, queued.ID, dueRetry.ID)
+	}
+
+	// Articles waiting for a retry still count as coverage of their story.
+	if len(ranker.corpus) != 4 {
+		t.Errorf("ranked against %d articles, want all 4 of the topic", len(ranker.corpus))
+	}
+
+	if len(topicSources) != 1 || topicSources[0].ID != 1 || len(ranker.sources) != 1 {
+		t.Errorf("rankedCandidates() sources = %v, want only source 1", topicSources)
+	}
+}
+
+func TestStalePendingReviewBlocksNextReview(t *testing.T) {
+	var (
+		fresh = model.Article{ID: 1, SourceID: 1, Status: model.ArticleStatusNew, PublishedAt: time.Now()}
+		// The pending article was published before the lookup window, so only ReviewedByTopicId returns it.
+		stale = model.Article{
+			ID:          2,
+			SourceID:    1,
+			Status:      model.ArticleStatusPendingReview,
+			PublishedAt: time.Now().Add(-48 * time.Hour),
+		}
+
+		articles = &fakeArticles{
+			byTopic:  map[int64][]model.Article{1: {fresh}},
+			reviewed: map[int64][]model.Article{1: {stale}},
+		}
+		sender = &fakeSender{}
+	)
+
+	n := newReviewTestNotifier(articles, []model.Source{{ID: 1, TopicID: 1}}, sender, 100)
+
+	if err := n.SelectAndSendArticle(context.Background()); err != nil {
+		t.Fatalf("SelectAndSendArticle() error = %v", err)
+	}
+
+	if len(sender.sent) != 0 {
+		t.Errorf("sent %d messages, want none while article %d awaits review", len(sender.sent), stale.ID)
+	}
+}
+
+func TestStaleApprovedArticleStaysCandidate(t *testing.T) {
+	var (
+		approved = model.Article{
+			ID:          1,
+			SourceID:    1,
+			Status:      model.ArticleStatusApproved,
+			PublishedAt: time.Now().Add(-48 * time.Hour),
+		}
+
+		articles = &fakeArticles{reviewed: map[int64][]model.Article{1: {approved}}}
+		sources  = []model.Source{{ID: 1, TopicID: 1}}
+	)
+
+	n := newReviewTestNotifier(articles, sources, &fakeSender{}, 100)
+
+	candidates, _, err := n.rankedCandidates(context.Background(), 1, sources)
+	if err != nil {
+		t.Fatalf("rankedCandidates() error = %v", err)
+	}
+
+	if len(candidates) != 1 || candidates[0].ID != approved.ID {
+		t.Errorf("rankedCandidates() = %v, want approved article %d", candidates, approved.ID)
+	}
+}
@@ -0,0 +1,76 @@
+package notifier
+
+import (
+	"context"
+	"log"
+	"tg-bot/internal/model"
+)
+
+// Recover settles articles left mid-publishing by a previous run. Deliveries and cross-posts that were
+// being sent are kept as unconfirmed instead of being sent again, since the message may already be out;
+// /repost publishes such an article again. Running it more than once changes nothing.
+func (n *Notifier) Recover(ctx context.Context) error {
+	unconfirmed, err := n.articles.UnconfirmDeliveries(ctx)
+	if err != nil {
+		return err
+	}
+
+	if unconfirmed > 0 {
+		log.Printf("[WARN] %d deliveries were interrupted while sending and are left unconfirmed", unconfirmed)
+	}
+
+	unconfirmed, err = n.destinations.UnconfirmCrossposts(ctx)
+	if err != nil {
+		return err
+	}
+
+	if unconfirmed > 0 {
+		log.Printf("[WARN] %d cross-posts were interrupted while sending and are left unconfirmed", unconfirmed)
+	}
+
+	if err := n.articles.ReplacePublishState(ctx, model.PublishSelected, model.PublishQueued); err != nil {
+		return err
+	}
+
+	sending, err := n.articles.ArticlesByPublishState(ctx, model.PublishSending)
+	if err != nil {
+		return err
+	}
+
+	for _, article := range sending {
+		if err := n.settle(ctx, article); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// settle marks an interrupted article posted if every channel of its topic has a delivery and
+// returns it to the summarized state otherwise.
+func (n *Notifier) settle(ctx context.Context, article model.Article) error {
+	source, err := n.sources.SourceById(ctx, article.SourceID)
+	if err != nil {
+		return err
+	}
+
+	channels, err := n.channels.ChannelsByTopicId(ctx, source.TopicID)
+	if err != nil {
+		return err
+	}
+
+	if len(channels) > 0 {
+		return n.markPostedIfDelivered(ctx, article.ID, channels)
+	}
+
+	posts, err := n.articles.PostsByArticleId(ctx, article.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(posts) == 0 {
+		return n.articles.SetPublishState(ctx, article.ID, model.PublishSummarized, "")
+	}
+
+	return n.articles.MarkPostedById(ctx, article.ID)
+}
@@ -0,0 +1,79 @@
+package notifier
+
+import (
+	"context"
+	"github.com/samber/lo"
+	"tg-bot/internal/model"
+	"tg-bot/internal/schedule"
+)
+
+const defaultChannelName = "default channel"
+
+// Preview is the article the notifier would post next for a topic.
+type Preview struct {
+	TopicID  int64
+	Article  model.Article
+	Summary  string
+	Channels []string
+	Err      error
+}
+
+// Preview returns the article every topic would post next with its summary, without sending or
+// storing anything. A summary that doesn't exist yet is generated. Channel schedules and digests
+// are not taken into account.
+func (n *Notifier) Preview(ctx context.Context) ([]Preview, error) {
+	sources, err := n.sources.Sources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var previews []Preview
+
+	for _, topicId := range getUniqueTopicIds(sources) {
+		candidates, sourcesForTopicId, err := n.rankedCandidates(ctx, topicId, sources)
+		if err != nil {
+			return nil, err
+		}
+
+		article, ok := lo.Find(candidates, n.publishable)
+		if !ok {
+			continue
+		}
+
+		channels, err := n.channels.ChannelsByTopicId(ctx, topicId)
+		if err != nil {
+			return nil, err
+		}
+
+		preview := Preview{
+			TopicID: topicId,
+			Article: article,
+			Summary: article.PostSummary(),
+			Channels: lo.FilterMap(channels, func(channel model.Channel, _ int) (string, bool) {
+				return channel.Name, channel.DigestMode == schedule.DigestOff
+			}),
+		}
+
+		if len(channels) == 0 {
+			preview.Channels = []string{defaultChannelName}
+		}
+
+		if preview.Summary == "" {
+			postSource, _ := lo.Find(sourcesForTopicId, func(source model.Source) bool {
+				return source.ID == article.SourceID
+			})
+
+			summary, err := n.extractSummary(ctx, article, postSource.Type)
+			preview.Summary, preview.Err = summary.Text, err
+		}
+
+		previews = append(previews, preview)
+	}
+
+	return previews, nil
+}
+
+// Summarize summarizes the page at the link like a post of the source type, for trying out prompts.
+func (n *Notifier) Summarize(ctx context.Context, link string, sourceType string) (model.Summary, error) {
+	return n.extractSummary(ctx, model.Article{Link: link}, sourceType)
+}
@@ -0,0 +1,108 @@
+package notifier
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"tg-bot/internal/model"
+)
+
+// Unpost deletes the messages of a posted article and returns it to the unposted state. A delivery is
+// forgotten only once Telegram has deleted its message, and posted_at is cleared only when every message
+// is gone, so a failed delete leaves the article posted in the chats its messages are still in.
+func (n *Notifier) Unpost(ctx context.Context, articleId int64) error {
+	posts, err := n.articles.PostsByArticleId(ctx, articleId)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		if post.MessageID == 0 {
+			log.Printf("delivery of article %d to chat %d has no confirmed message, nothing to delete", articleId, post.ChatID)
+			continue
+		}
+
+		shared, err := n.articles.CountPostsByMessage(ctx, post.ChatID, post.MessageID)
+		if err != nil {
+			return err
+		}
+
+		if shared > 1 {
+			log.Printf("message %d in chat %d is a digest, keeping it", post.MessageID, post.ChatID)
+			continue
+		}
+
+		if _, err := n.sender.Request(tgbotapi.NewDeleteMessage(post.ChatID, post.MessageID)); err != nil {
+			return err
+		}
+
+		if err := n.articles.DeletePost(ctx, post.ID); err != nil {
+			return err
+		}
+	}
+
+	return n.articles.ResetPosted(ctx, articleId)
+}
+
+// Repost deletes the article's messages and publishes it again with a freshly generated summary
+// to every channel of its topic, regardless of channel schedules. Its earlier cross-posts are
+// forgotten, so the new summary is cross-posted again as well. The summary is generated and stored
+// before anything is deleted, and the article is approved again before publishing, so a failure at any
+// point leaves it either posted or queued for the notifier to deliver.
+func (n *Notifier) Repost(ctx context.Context, articleId int64) error {
+	article, err := n.articles.ArticleById(ctx, articleId)
+	if err != nil {
+		return err
+	}
+
+	summary, err := n.GenerateSummary(ctx, *article)
+	if err != nil {
+		return err
+	}
+
+	if err := n.articles.SetGeneratedSummary(ctx, articleId, summary); err != nil {
+		return err
+	}
+
+	source, err := n.sources.SourceById(ctx, article.SourceID)
+	if err != nil {
+		return err
+	}
+
+	channels, err := n.channels.ChannelsByTopicId(ctx, source.TopicID)
+	if err != nil {
+		return err
+	}
+
+	if err := n.Unpost(ctx, articleId); err != nil {
+		return err
+	}
+
+	if err := n.destinations.ForgetCrossposts(ctx, articleId); err != nil {
+		return err
+	}
+
+	if err := n.articles.SetStatus(ctx, articleId, model.ArticleStatusApproved); err != nil {
+		return err
+	}
+
+	// Unposting reset the publish state and attempts.
+	article, err = n.articles.ArticleById(ctx, articleId)
+	if err != nil {
+		return err
+	}
+
+	if len(channels) == 0 {
+		if err := n.publish(ctx, *article, 0, n.channelId, summary.Text); err != nil {
+			return err
+		}
+	}
+
+	for _, channel := range channels {
+		if err := n.publish(ctx, *article, channel.ID, channel.ChatID, summary.Text); err != nil {
+			return err
+		}
+	}
+
+	return n.articles.MarkPostedById(ctx, articleId)
+}
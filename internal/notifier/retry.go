@@ -0,0 +1,57 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"tg-bot/internal/config"
+	"tg-bot/internal/model"
+	"time"
+)
+
+// recordFailure counts a failed attempt to publish the article and returns the cause.
+func (n *Notifier) recordFailure(ctx context.Context, article model.Article, cause error) error {
+	return errors.Join(fmt.Errorf("article %d: %w", article.ID, cause), n.storeFailure(ctx, article, cause))
+}
+
+// storeFailure counts a failed attempt to publish the article. The article is retried after a delay
+// that doubles with every attempt and is given up as dead once it runs out of attempts.
+func (n *Notifier) storeFailure(ctx context.Context, article model.Article, cause error) error {
+	attempts := article.PublishAttempts + 1
+
+	state := model.PublishFailed
+	if attempts >= config.Get().PublishMaxAttempts {
+		state = model.PublishDead
+	}
+
+	log.Printf("[ERROR] Failed to publish article %d, attempt %d, %s: %v", article.ID, attempts, state, cause)
+
+	return n.articles.RecordPublishFailure(ctx, article.ID, state, cause.Error(), time.Now().Add(retryDelay(attempts)))
+}
+
+func retryDelay(attempts int) time.Duration {
+	var (
+		delay    = config.Get().PublishRetryDelay
+		maxDelay = config.Get().PublishMaxRetryDelay
+	)
+
+	for i := 1; i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxDelay)
+}
+
+// retryDue reports whether the article may be attempted now: dead articles never are and failed ones
+// only once their backoff has passed.
+func retryDue(article model.Article, now time.Time) bool {
+	switch article.PublishState {
+	case model.PublishDead:
+		return false
+	case model.PublishFailed:
+		return !now.Before(article.NextAttemptAt)
+	default:
+		return true
+	}
+}
@@ -0,0 +1,52 @@
+package notifier
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/samber/lo"
+	"tg-bot/internal/model"
+	"tg-bot/internal/review"
+)
+
+// sendForReview sends the best new article of the topic to the review chat unless the topic
+// already has an article awaiting review.
+func (n *Notifier) sendForReview(ctx context.Context, candidates []model.Article, sources []model.Source) error {
+	if lo.ContainsBy(candidates, func(article model.Article) bool {
+		return article.Status == model.ArticleStatusPendingReview
+	}) {
+		return nil
+	}
+
+	article, ok := lo.Find(candidates, func(article model.Article) bool {
+		return article.Status == model.ArticleStatusNew
+	})
+	if !ok {
+		return nil
+	}
+
+	summary, err := n.summaryFor(ctx, article, sources, make(map[int64]string))
+	if err != nil {
+		return n.recordFailure(ctx, article, err)
+	}
+
+	msg := tgbotapi.NewMessage(n.reviewChatId, review.Preview(article, summary))
+	msg.ParseMode = tgbotapi.ModeMarkdownV2
+	msg.ReplyMarkup = review.Keyboard(article.ID)
+
+	sent, err := n.sender.Send(msg)
+	if err != nil {
+		return err
+	}
+
+	return n.articles.MarkPendingReview(ctx, article.ID, sent.MessageID)
+}
+
+// publishable reports whether the article may go out: rejected articles never do, and in review
+// mode only approved ones do.
+func (n *Notifier) publishable(article model.Article) bool {
+	if n.reviewChatId != 0 {
+		return article.Status == model.ArticleStatusApproved
+	}
+
+	return article.Status != model.ArticleStatusRejected
+}
@@ -0,0 +1,68 @@
+package publisher
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultBlueskyServer = "https://bsky.social"
+
+	blueskyPostLength        = 300
+	blueskyCardTitleLength   = 300
+	blueskyCardSummaryLength = 1000
+)
+
+// Bluesky posts to a Bluesky account with an app password. Bluesky does not turn links in the text
+// into cards, so the article link goes into an external embed instead.
+type Bluesky struct {
+	server   string
+	handle   string
+	password string
+}
+
+type blueskySession struct {
+	AccessJwt string `json:"accessJwt"`
+	DID       string `json:"did"`
+}
+
+// NewBluesky returns the publisher of the account on the given server, bsky.social when it is empty.
+func NewBluesky(server string, handle string, password string) *Bluesky {
+	if server == "" {
+		server = DefaultBlueskyServer
+	}
+
+	return &Bluesky{server: strings.TrimRight(server, "/"), handle: handle, password: password}
+}
+
+func (b *Bluesky) Publish(ctx context.Context, post Post) error {
+	var session blueskySession
+	if err := sendJSON(ctx, http.MethodPost, b.server+"/xrpc/com.atproto.server.createSession", nil,
+		map[string]string{
+			"identifier": b.handle,
+			"password":   b.password,
+		}, &session); err != nil {
+		return err
+	}
+
+	return sendJSON(ctx, http.MethodPost, b.server+"/xrpc/com.atproto.repo.createRecord", bearer(session.AccessJwt),
+		map[string]any{
+			"repo":       session.DID,
+			"collection": "app.bsky.feed.post",
+			"record": map[string]any{
+				"$type":     "app.bsky.feed.post",
+				"text":      shortPost(post, blueskyPostLength),
+				"createdAt": time.Now().UTC().Format(time.RFC3339),
+				"embed": map[string]any{
+					"$type": "app.bsky.embed.external",
+					"external": map[string]string{
+						"uri":         post.Link,
+						"title":       truncate(post.Title, blueskyCardTitleLength),
+						"description": truncate(post.Summary, blueskyCardSummaryLength),
+					},
+				},
+			},
+		}, nil)
+}
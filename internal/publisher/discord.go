@@ -0,0 +1,31 @@
+package publisher
+
+import (
+	"context"
+	"net/http"
+)
+
+// Discord embed limits.
+const (
+	discordTitleLength       = 256
+	discordDescriptionLength = 4096
+)
+
+// Discord posts an embed to a Discord channel webhook.
+type Discord struct {
+	url string
+}
+
+func NewDiscord(url string) *Discord {
+	return &Discord{url: url}
+}
+
+func (d *Discord) Publish(ctx context.Context, post Post) error {
+	return sendJSON(ctx, http.MethodPost, d.url, nil, map[string]any{
+		"embeds": []map[string]any{{
+			"title":       truncate(post.Title, discordTitleLength),
+			"url":         post.Link,
+			"description": truncate(post.Summary, discordDescriptionLength),
+		}},
+	}, nil)
+}
@@ -0,0 +1,38 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	mastodonStatusLength = 500
+	// mastodonLinkLength is how much any link takes of the status length, whatever its real length.
+	mastodonLinkLength = 23
+)
+
+// Mastodon posts a public status to a Mastodon compatible server. The server renders the preview
+// card of the article link at the end of the status.
+type Mastodon struct {
+	server string
+	token  string
+}
+
+func NewMastodon(server string, token string) *Mastodon {
+	return &Mastodon{server: strings.TrimRight(server, "/"), token: token}
+}
+
+func (m *Mastodon) Publish(ctx context.Context, post Post) error {
+	status := shortPost(post, mastodonStatusLength-mastodonLinkLength-len("\n\n")) + "\n\n" + post.Link
+
+	header := bearer(m.token)
+	// The server drops a repeated status with the same key, so a retried request doesn't post twice.
+	header.Set("Idempotency-Key", fmt.Sprintf("article-%d", post.ArticleID))
+
+	return sendJSON(ctx, http.MethodPost, m.server+"/api/v1/statuses", header, map[string]any{
+		"status":     status,
+		"visibility": "public",
+	}, nil)
+}
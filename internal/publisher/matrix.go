@@ -0,0 +1,42 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Matrix sends a message to a Matrix room through the client-server API of the homeserver.
+type Matrix struct {
+	homeserver string
+	token      string
+	room       string
+}
+
+func NewMatrix(homeserver string, token string, room string) *Matrix {
+	return &Matrix{homeserver: strings.TrimRight(homeserver, "/"), token: token, room: room}
+}
+
+func (m *Matrix) Publish(ctx context.Context, post Post) error {
+	// The transaction ID makes the homeserver drop a repeated send of the same article.
+	sendURL := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/article-%d",
+		m.homeserver, url.PathEscape(m.room), post.ArticleID)
+
+	body := post.Title
+	formatted := fmt.Sprintf(`<a href="%s"><b>%s</b></a>`, html.EscapeString(post.Link), html.EscapeString(post.Title))
+
+	if post.Summary != "" {
+		body += "\n\n" + post.Summary
+		formatted += "<br><br>" + strings.ReplaceAll(html.EscapeString(post.Summary), "\n", "<br>")
+	}
+
+	return sendJSON(ctx, http.MethodPut, sendURL, bearer(m.token), map[string]any{
+		"msgtype":        "m.text",
+		"body":           body + "\n\n" + post.Link,
+		"format":         "org.matrix.custom.html",
+		"formatted_body": formatted,
+	}, nil)
+}
@@ -0,0 +1,171 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"tg-bot/internal/model"
+	"time"
+)
+
+const (
+	TypeWebhook  = "webhook"
+	TypeSlack    = "slack"
+	TypeDiscord  = "discord"
+	TypeMatrix   = "matrix"
+	TypeMastodon = "mastodon"
+	TypeBluesky  = "bluesky"
+
+	requestTimeout = 15 * time.Second
+)
+
+var httpClient = &http.Client{Timeout: requestTimeout}
+
+// Post is an article with the summary it is published with.
+type Post struct {
+	ArticleID   int64     `json:"article_id"`
+	Title       string    `json:"title"`
+	Summary     string    `json:"summary"`
+	Link        string    `json:"link"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+func NewPost(article model.Article, summary string) Post {
+	return Post{
+		ArticleID:   article.ID,
+		Title:       article.Title,
+		Summary:     summary,
+		Link:        article.Link,
+		PublishedAt: article.PublishedAt,
+	}
+}
+
+// Publisher delivers posts to one destination.
+type Publisher interface {
+	Publish(ctx context.Context, post Post) error
+}
+
+// New returns the publisher of a cross-post destination.
+func New(destination model.Destination) (Publisher, error) {
+	if err := Validate(destination); err != nil {
+		return nil, err
+	}
+
+	switch destination.Type {
+	case TypeSlack:
+		return NewSlack(destination.URL), nil
+	case TypeDiscord:
+		return NewDiscord(destination.URL), nil
+	case TypeMatrix:
+		return NewMatrix(destination.URL, destination.Token, destination.Room), nil
+	case TypeMastodon:
+		return NewMastodon(destination.URL, destination.Token), nil
+	case TypeBluesky:
+		return NewBluesky(destination.URL, destination.Account, destination.Token), nil
+	default:
+		return NewWebhook(destination.URL, destination.Token), nil
+	}
+}
+
+// Validate checks that the destination has everything its type needs.
+func Validate(destination model.Destination) error {
+	switch destination.Type {
+	case TypeWebhook, TypeSlack, TypeDiscord:
+	case TypeMatrix:
+		if destination.Token == "" || destination.Room == "" {
+			return errors.New("matrix destination needs an access token and a room")
+		}
+	case TypeMastodon:
+		if destination.Token == "" {
+			return errors.New("mastodon destination needs an access token")
+		}
+	case TypeBluesky:
+		if destination.Account == "" || destination.Token == "" {
+			return errors.New("bluesky destination needs an account handle and an app password")
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unknown destination type %q", destination.Type)
+	}
+
+	if destination.URL == "" {
+		return errors.New("destination URL is empty")
+	}
+
+	return nil
+}
+
+// sendJSON sends the payload and decodes the response into result unless it is nil. Any response
+// other than 2xx is an error.
+func sendJSON(ctx context.Context, method string, url string, header http.Header, payload any, result any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s: %s", method, req.URL.Host, resp.Status, respBody)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// bearer returns the authorization header of the token, or no header when the token is empty.
+func bearer(token string) http.Header {
+	header := make(http.Header)
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+
+	return header
+}
+
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+
+	if limit <= 0 {
+		return ""
+	}
+
+	return string(runes[:limit-1]) + "…"
+}
+
+// shortPost joins the title and as much of the summary as fits into limit characters.
+func shortPost(post Post, limit int) string {
+	text := truncate(post.Title, limit)
+
+	if room := limit - len([]rune(text)) - len("\n\n"); post.Summary != "" && room > 1 {
+		text += "\n\n" + truncate(post.Summary, room)
+	}
+
+	return text
+}
@@ -0,0 +1,31 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Slack posts to a Slack incoming webhook or any chat that accepts the same payload.
+type Slack struct {
+	url string
+}
+
+func NewSlack(url string) *Slack {
+	return &Slack{url: url}
+}
+
+func (s *Slack) Publish(ctx context.Context, post Post) error {
+	text := fmt.Sprintf("*<%s|%s>*", post.Link, slackEscaper.Replace(post.Title))
+	if post.Summary != "" {
+		text += "\n\n" + slackEscaper.Replace(post.Summary)
+	}
+
+	return sendJSON(ctx, http.MethodPost, s.url, nil, map[string]any{
+		"text":         text,
+		"unfurl_links": true,
+	}, nil)
+}
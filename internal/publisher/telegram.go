@@ -0,0 +1,27 @@
+package publisher
+
+import (
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"tg-bot/internal/botkit/markup"
+)
+
+// NewTelegramMessage formats the post as a message to a Telegram chat, sent by the notifier through the bot.
+func NewTelegramMessage(chatId int64, post Post) tgbotapi.MessageConfig {
+	const msgFormat = "*%s*%s\n\n%s"
+
+	summary := post.Summary
+	if summary != "" {
+		summary = "\n\n" + summary
+	}
+
+	msg := tgbotapi.NewMessage(chatId, fmt.Sprintf(
+		msgFormat,
+		markup.EscapeForMarkdown(post.Title),
+		markup.EscapeForMarkdown(summary),
+		markup.EscapeForMarkdown(post.Link),
+	))
+	msg.ParseMode = tgbotapi.ModeMarkdownV2
+
+	return msg
+}
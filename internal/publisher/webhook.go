@@ -0,0 +1,20 @@
+package publisher
+
+import (
+	"context"
+	"net/http"
+)
+
+// Webhook posts the article as JSON to any HTTP endpoint, with a bearer token if one is set.
+type Webhook struct {
+	url   string
+	token string
+}
+
+func NewWebhook(url string, token string) *Webhook {
+	return &Webhook{url: url, token: token}
+}
+
+func (w *Webhook) Publish(ctx context.Context, post Post) error {
+	return sendJSON(ctx, http.MethodPost, w.url, bearer(w.token), post, nil)
+}
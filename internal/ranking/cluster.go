@@ -0,0 +1,55 @@
+package ranking
+
+import (
+	"tg-bot/internal/model"
+
+	set "github.com/deckarep/golang-set/v2"
+)
+
+// Cluster groups articles telling the same story, comparing titles and, when present, titles
+// with summaries. An article joins the first cluster holding a similar article, so the order
+// of clusters and of articles within them follows the input order.
+func Cluster(articles []model.Article, threshold float64) [][]model.Article {
+	type member struct {
+		title   set.Set[string]
+		content set.Set[string]
+	}
+
+	var (
+		clusters [][]model.Article
+		members  [][]member
+	)
+
+	for _, article := range articles {
+		candidate := member{
+			title:   tokenize(article.Title),
+			content: tokenize(article.Title + " " + article.Summary),
+		}
+
+		joined := false
+
+		for i := range clusters {
+			for _, other := range members[i] {
+				if jaccard(candidate.title, other.title) >= threshold ||
+					jaccard(candidate.content, other.content) >= threshold {
+					clusters[i] = append(clusters[i], article)
+					members[i] = append(members[i], candidate)
+					joined = true
+
+					break
+				}
+			}
+
+			if joined {
+				break
+			}
+		}
+
+		if !joined {
+			clusters = append(clusters, []model.Article{article})
+			members = append(members, []member{candidate})
+		}
+	}
+
+	return clusters
+}
@@ -0,0 +1,115 @@
+package ranking
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"tg-bot/internal/model"
+	"time"
+
+	set "github.com/deckarep/golang-set/v2"
+	"github.com/samber/lo"
+)
+
+// SameStoryThreshold is the title similarity above which two articles are considered the same story.
+const SameStoryThreshold = 0.5
+
+type Scorer struct {
+	halfLife      time.Duration
+	boostKeywords []string
+	keywordBoost  float64
+	coverageBoost float64
+}
+
+func NewScorer(halfLife time.Duration, boostKeywords []string, keywordBoost float64, coverageBoost float64) *Scorer {
+	return &Scorer{
+		halfLife: halfLife,
+		boostKeywords: lo.Map(boostKeywords, func(keyword string, _ int) string {
+			return strings.ToLower(keyword)
+		}),
+		keywordBoost:  keywordBoost,
+		coverageBoost: coverageBoost,
+	}
+}
+
+// Rank orders candidates from the highest score to the lowest. corpus is the set of recent
+// articles used to estimate how many sources cover the same story.
+func (s *Scorer) Rank(candidates []model.Article, corpus []model.Article, sources []model.Source) []model.Article {
+	weights := lo.SliceToMap(sources, func(source model.Source) (int64, float64) {
+		return source.ID, source.Priority
+	})
+
+	var (
+		stories = tokenizeTitles(corpus)
+		scores  = make(map[int64]float64, len(candidates))
+	)
+
+	for _, article := range candidates {
+		scores[article.ID] = s.score(article, weights[article.SourceID], stories)
+	}
+
+	ranked := make([]model.Article, len(candidates))
+	copy(ranked, candidates)
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i].ID] > scores[ranked[j].ID]
+	})
+
+	return ranked
+}
+
+// score combines the source weight, recency decay, keyword boosts and cross-source coverage.
+func (s *Scorer) score(article model.Article, sourceWeight float64, stories []story) float64 {
+	return sourceWeight *
+		s.recency(article) *
+		(1 + s.keywordBoost*float64(s.keywordMatches(article))) *
+		(1 + s.coverageBoost*float64(coverage(article, stories)-1))
+}
+
+func (s *Scorer) recency(article model.Article) float64 {
+	if s.halfLife <= 0 {
+		return 1
+	}
+
+	age := time.Since(article.PublishedAt)
+	if age < 0 {
+		age = 0
+	}
+
+	return math.Pow(0.5, age.Hours()/s.halfLife.Hours())
+}
+
+func (s *Scorer) keywordMatches(article model.Article) int {
+	text := strings.ToLower(article.Title + " " + article.Summary)
+
+	return lo.CountBy(s.boostKeywords, func(keyword string) bool {
+		return strings.Contains(text, keyword)
+	})
+}
+
+// coverage counts the distinct sources that published the same story, including the article's own.
+func coverage(article model.Article, stories []story) int {
+	var (
+		sources = set.NewThreadUnsafeSet[int64](article.SourceID)
+		tokens  = tokenize(article.Title)
+	)
+
+	for _, other := range stories {
+		if jaccard(tokens, other.tokens) >= SameStoryThreshold {
+			sources.Add(other.sourceID)
+		}
+	}
+
+	return sources.Cardinality()
+}
+
+type story struct {
+	sourceID int64
+	tokens   set.Set[string]
+}
+
+func tokenizeTitles(articles []model.Article) []story {
+	return lo.Map(articles, func(article model.Article, _ int) story {
+		return story{sourceID: article.SourceID, tokens: tokenize(article.Title)}
+	})
+}
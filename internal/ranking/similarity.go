@@ -0,0 +1,35 @@
+package ranking
+
+import (
+	"strings"
+	"unicode"
+
+	set "github.com/deckarep/golang-set/v2"
+)
+
+const minTokenLength = 3
+
+// jaccard returns the Jaccard index of two sets of significant words.
+func jaccard(tokensA, tokensB set.Set[string]) float64 {
+	if tokensA.Cardinality() == 0 || tokensB.Cardinality() == 0 {
+		return 0
+	}
+
+	return float64(tokensA.Intersect(tokensB).Cardinality()) / float64(tokensA.Union(tokensB).Cardinality())
+}
+
+func tokenize(text string) set.Set[string] {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := set.NewThreadUnsafeSet[string]()
+
+	for _, word := range words {
+		if len([]rune(word)) >= minTokenLength {
+			tokens.Add(word)
+		}
+	}
+
+	return tokens
+}
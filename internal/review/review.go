@@ -0,0 +1,84 @@
+package review
+
+import (
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"regexp"
+	"strconv"
+	"strings"
+	"tg-bot/internal/botkit/markup"
+	"tg-bot/internal/model"
+)
+
+const (
+	CallbackPrefix = "review"
+
+	ActionApprove    = "approve"
+	ActionEdit       = "edit"
+	ActionReject     = "reject"
+	ActionRegenerate = "regenerate"
+)
+
+var editPromptRegexp = regexp.MustCompile(`article #(\d+)`)
+
+// Preview renders an article awaiting review in MarkdownV2.
+func Preview(article model.Article, summary string) string {
+	return fmt.Sprintf(
+		"*%s*\n\n%s\n\n%s\n\nArticle ID: `%d`",
+		markup.EscapeForMarkdown(article.Title),
+		markup.EscapeForMarkdown(summary),
+		markup.EscapeForMarkdown(article.Link),
+		article.ID,
+	)
+}
+
+func Keyboard(articleId int64) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Approve", CallbackData(ActionApprove, articleId)),
+			tgbotapi.NewInlineKeyboardButtonData("✏️ Edit", CallbackData(ActionEdit, articleId)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("❌ Reject", CallbackData(ActionReject, articleId)),
+			tgbotapi.NewInlineKeyboardButtonData("🔄 Regenerate", CallbackData(ActionRegenerate, articleId)),
+		),
+	)
+}
+
+func CallbackData(action string, articleId int64) string {
+	return fmt.Sprintf("%s:%s:%d", CallbackPrefix, action, articleId)
+}
+
+// ParseCallbackData extracts the action and article ID from review button data.
+func ParseCallbackData(data string) (string, int64, error) {
+	parts := strings.Split(data, ":")
+	if len(parts) != 3 || parts[0] != CallbackPrefix {
+		return "", 0, fmt.Errorf("unexpected review callback data %q", data)
+	}
+
+	articleId, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return parts[1], articleId, nil
+}
+
+func EditPrompt(articleId int64) string {
+	return fmt.Sprintf("Reply to this message or to the preview with the new summary of article #%d", articleId)
+}
+
+// ParseEditPrompt returns the article ID of an edit prompt message.
+func ParseEditPrompt(text string) (int64, bool) {
+	match := editPromptRegexp.FindStringSubmatch(text)
+	if match == nil {
+		return 0, false
+	}
+
+	articleId, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return articleId, true
+}
@@ -0,0 +1,74 @@
+package schedule
+
+import (
+	"fmt"
+	"github.com/samber/lo"
+	"tg-bot/internal/model"
+	"time"
+)
+
+const (
+	DigestOff    = ""
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// ValidateDigest checks digest settings before they are saved.
+func ValidateDigest(mode string, clock string) error {
+	if mode != DigestOff && mode != DigestDaily && mode != DigestWeekly {
+		return fmt.Errorf("unknown digest mode %q: expected %q or %q", mode, DigestDaily, DigestWeekly)
+	}
+
+	if _, err := time.Parse(clockLayout, clock); err != nil {
+		return fmt.Errorf("invalid digest time %q: %w", clock, err)
+	}
+
+	return nil
+}
+
+// DigestDue reports whether the channel has missed its most recent digest slot.
+func DigestDue(channel model.Channel, defaultTimezone string, now time.Time) (bool, error) {
+	if channel.DigestMode == DigestOff {
+		return false, nil
+	}
+
+	slot, err := lastDigestSlot(channel, defaultTimezone, now)
+	if err != nil {
+		return false, err
+	}
+
+	return channel.LastDigestAt.Before(slot), nil
+}
+
+func lastDigestSlot(channel model.Channel, defaultTimezone string, now time.Time) (time.Time, error) {
+	location, err := time.LoadLocation(lo.Ternary(channel.Timezone != "", channel.Timezone, defaultTimezone))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	clock, err := time.Parse(clockLayout, channel.DigestTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var (
+		local = now.In(location)
+		slot  = time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, location)
+	)
+
+	if channel.DigestMode == DigestWeekly {
+		slot = slot.AddDate(0, 0, -int((local.Weekday()-channel.DigestWeekday+7)%7))
+
+		if slot.After(local) {
+			slot = slot.AddDate(0, 0, -7)
+		}
+
+		return slot, nil
+	}
+
+	if slot.After(local) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+
+	return slot, nil
+}
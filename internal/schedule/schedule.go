@@ -0,0 +1,123 @@
+package schedule
+
+import (
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"github.com/samber/lo"
+	"strings"
+	"tg-bot/internal/model"
+	"time"
+)
+
+const clockLayout = "15:04"
+
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// Schedule decides when a channel accepts new posts.
+type Schedule struct {
+	location        *time.Location
+	window          cron.Schedule
+	quietFrom       time.Duration
+	quietTo         time.Duration
+	hasQuietHours   bool
+	maxPostsPerHour int
+}
+
+// ForChannel builds the channel's schedule, using defaultTimezone when the channel has none.
+func ForChannel(channel model.Channel, defaultTimezone string) (*Schedule, error) {
+	return New(
+		lo.Ternary(channel.Timezone != "", channel.Timezone, defaultTimezone),
+		channel.Schedule,
+		channel.QuietHours,
+		channel.MaxPostsPerHour,
+	)
+}
+
+// New parses a schedule. window is a five-field cron expression matching the minutes when
+// posting is allowed, quietHours is a "HH:MM-HH:MM" range when it is not. Empty values disable
+// the respective restriction, as does a non-positive maxPostsPerHour.
+func New(timezone string, window string, quietHours string, maxPostsPerHour int) (*Schedule, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Schedule{
+		location:        location,
+		maxPostsPerHour: maxPostsPerHour,
+	}
+
+	if window != "" {
+		s.window, err = cronParser.Parse(window)
+		if err != nil {
+			return nil, fmt.Errorf("invalid posting window %q: %w", window, err)
+		}
+	}
+
+	if quietHours != "" {
+		if s.quietFrom, s.quietTo, err = parseQuietHours(quietHours); err != nil {
+			return nil, err
+		}
+
+		s.hasQuietHours = true
+	}
+
+	return s, nil
+}
+
+// IsOpen reports whether posting is allowed at t. The window is open when it matched any minute from
+// since up to t, so that a window is not missed when the notifier ticks less often than once a minute.
+func (s *Schedule) IsOpen(t time.Time, since time.Time) bool {
+	local := t.In(s.location)
+
+	if s.window != nil {
+		from := lo.Ternary(since.Before(t), since, t).In(s.location).Truncate(time.Minute)
+		if s.window.Next(from.Add(-time.Second)).After(local.Truncate(time.Minute)) {
+			return false
+		}
+	}
+
+	return !s.inQuietHours(local)
+}
+
+// AllowsMore reports whether another post fits into the hourly limit.
+func (s *Schedule) AllowsMore(postedLastHour int) bool {
+	return s.maxPostsPerHour <= 0 || postedLastHour < s.maxPostsPerHour
+}
+
+func (s *Schedule) inQuietHours(local time.Time) bool {
+	if !s.hasQuietHours {
+		return false
+	}
+
+	clock := sinceMidnight(local)
+
+	if s.quietFrom <= s.quietTo {
+		return clock >= s.quietFrom && clock < s.quietTo
+	}
+
+	return clock >= s.quietFrom || clock < s.quietTo
+}
+
+func parseQuietHours(quietHours string) (time.Duration, time.Duration, error) {
+	from, to, ok := strings.Cut(quietHours, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid quiet hours %q: expected HH:MM-HH:MM", quietHours)
+	}
+
+	fromTime, err := time.Parse(clockLayout, strings.TrimSpace(from))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid quiet hours %q: %w", quietHours, err)
+	}
+
+	toTime, err := time.Parse(clockLayout, strings.TrimSpace(to))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid quiet hours %q: %w", quietHours, err)
+	}
+
+	return sinceMidnight(fromTime), sinceMidnight(toTime), nil
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+}
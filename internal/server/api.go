@@ -0,0 +1,335 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"tg-bot/internal/model"
+	"time"
+)
+
+const defaultPageSize int64 = 50
+
+type SourceStore interface {
+	Sources(ctx context.Context) ([]model.Source, error)
+	SourceById(ctx context.Context, id int64) (*model.Source, error)
+	Save(ctx context.Context, source model.Source) (int64, error)
+	Update(ctx context.Context, source model.Source) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type TopicStore interface {
+	Topics(ctx context.Context) ([]model.Topic, error)
+	TopicById(ctx context.Context, id int64) (*model.Topic, error)
+	Save(ctx context.Context, topic model.Topic) (int64, error)
+	Update(ctx context.Context, topic model.Topic) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type FilterStore interface {
+	Filters(ctx context.Context) ([]model.Filter, error)
+	Save(ctx context.Context, filter model.Filter) (int64, error)
+	Update(ctx context.Context, filter model.Filter) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type ArticleStore interface {
+	Articles(ctx context.Context, limit int64, offset int64) ([]model.Article, error)
+	ArticleById(ctx context.Context, id int64) (*model.Article, error)
+	Create(ctx context.Context, article model.Article) (int64, error)
+	SetStatus(ctx context.Context, id int64, status string) error
+	SetEditedSummary(ctx context.Context, id int64, summary string) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// Trigger asks the loop of a pipeline step, like the fetcher or the notifier, to run right away. It
+// reports false if a run is already waiting.
+type Trigger func() bool
+
+// AdminAPI is what the admin API manages.
+type AdminAPI struct {
+	Sources  SourceStore
+	Topics   TopicStore
+	Filters  FilterStore
+	Articles ArticleStore
+	// MinRetentionDays is the shortest retention a topic may override, see cleaner.MinRetentionDays.
+	MinRetentionDays int
+	FetchNow         Trigger
+	PostNow          Trigger
+}
+
+var articleStatuses = []string{
+	model.ArticleStatusNew,
+	model.ArticleStatusPendingReview,
+	model.ArticleStatusApproved,
+	model.ArticleStatusRejected,
+}
+
+type articlePatch struct {
+	Status        *string `json:"status"`
+	EditedSummary *string `json:"editedSummary"`
+}
+
+// HandleAdminAPI serves the JSON admin API under /api. Every request must carry the token as a bearer token.
+func (s *Server) HandleAdminAPI(token string, api AdminAPI) {
+	handle := func(pattern string, handler http.HandlerFunc) {
+		s.Handle(pattern, requireToken(token, handler))
+	}
+
+	handle("GET /api/sources", func(w http.ResponseWriter, r *http.Request) {
+		sources, err := api.Sources.Sources(r.Context())
+		writeResult(w, sources, err)
+	})
+	handle("POST /api/sources", func(w http.ResponseWriter, r *http.Request) {
+		var source model.Source
+		if !readJSON(w, r, &source) {
+			return
+		}
+
+		if err := validateSource(&source, true); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		id, err := api.Sources.Save(r.Context(), source)
+		writeCreated(w, id, err)
+	})
+	handle("GET /api/sources/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := pathId(w, r); ok {
+			source, err := api.Sources.SourceById(r.Context(), id)
+			writeResult(w, source, err)
+		}
+	})
+	handle("PUT /api/sources/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var source model.Source
+		if id, ok := pathId(w, r); ok && readJSON(w, r, &source) {
+			if err := validateSource(&source, false); err != nil {
+				writeError(w, err)
+				return
+			}
+
+			source.ID = id
+			writeResult(w, source, api.Sources.Update(r.Context(), source))
+		}
+	})
+	handle("DELETE /api/sources/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := pathId(w, r); ok {
+			writeDeleted(w, api.Sources.Delete(r.Context(), id))
+		}
+	})
+
+	handle("GET /api/topics", func(w http.ResponseWriter, r *http.Request) {
+		topics, err := api.Topics.Topics(r.Context())
+		writeResult(w, topics, err)
+	})
+	handle("POST /api/topics", func(w http.ResponseWriter, r *http.Request) {
+		var topic model.Topic
+		if !readJSON(w, r, &topic) {
+			return
+		}
+
+		if err := validateTopic(topic, api.MinRetentionDays); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		id, err := api.Topics.Save(r.Context(), topic)
+		writeCreated(w, id, err)
+	})
+	handle("GET /api/topics/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := pathId(w, r); ok {
+			topic, err := api.Topics.TopicById(r.Context(), id)
+			writeResult(w, topic, err)
+		}
+	})
+	handle("PUT /api/topics/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var topic model.Topic
+		if id, ok := pathId(w, r); ok && readJSON(w, r, &topic) {
+			if err := validateTopic(topic, api.MinRetentionDays); err != nil {
+				writeError(w, err)
+				return
+			}
+
+			topic.ID = id
+			writeResult(w, topic, api.Topics.Update(r.Context(), topic))
+		}
+	})
+	handle("DELETE /api/topics/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := pathId(w, r); ok {
+			writeDeleted(w, api.Topics.Delete(r.Context(), id))
+		}
+	})
+
+	handle("GET /api/filters", func(w http.ResponseWriter, r *http.Request) {
+		filters, err := api.Filters.Filters(r.Context())
+		writeResult(w, filters, err)
+	})
+	handle("POST /api/filters", func(w http.ResponseWriter, r *http.Request) {
+		var filter model.Filter
+		if !readJSON(w, r, &filter) {
+			return
+		}
+
+		if strings.TrimSpace(filter.Keyword) == "" {
+			http.Error(w, "keyword is empty", http.StatusBadRequest)
+			return
+		}
+
+		id, err := api.Filters.Save(r.Context(), filter)
+		writeCreated(w, id, err)
+	})
+	handle("PUT /api/filters/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var filter model.Filter
+		if id, ok := pathId(w, r); ok && readJSON(w, r, &filter) {
+			if strings.TrimSpace(filter.Keyword) == "" {
+				http.Error(w, "keyword is empty", http.StatusBadRequest)
+				return
+			}
+
+			filter.ID = id
+			writeResult(w, filter, api.Filters.Update(r.Context(), filter))
+		}
+	})
+	handle("DELETE /api/filters/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := pathId(w, r); ok {
+			writeDeleted(w, api.Filters.Delete(r.Context(), id))
+		}
+	})
+
+	handle("GET /api/articles", func(w http.ResponseWriter, r *http.Request) {
+		limit, offset, ok := page(w, r)
+		if !ok {
+			return
+		}
+
+		articles, err := api.Articles.Articles(r.Context(), limit, offset)
+		writeResult(w, articles, err)
+	})
+	handle("POST /api/articles", func(w http.ResponseWriter, r *http.Request) {
+		var article model.Article
+		if !readJSON(w, r, &article) {
+			return
+		}
+
+		if err := validateArticle(&article); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		id, err := api.Articles.Create(r.Context(), article)
+		writeCreated(w, id, err)
+	})
+	handle("GET /api/articles/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := pathId(w, r); ok {
+			article, err := api.Articles.ArticleById(r.Context(), id)
+			writeResult(w, article, err)
+		}
+	})
+	handle("PATCH /api/articles/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var patch articlePatch
+		if id, ok := pathId(w, r); ok && readJSON(w, r, &patch) {
+			if err := patchArticle(r.Context(), api.Articles, id, patch); err != nil {
+				writeError(w, err)
+				return
+			}
+
+			article, err := api.Articles.ArticleById(r.Context(), id)
+			writeResult(w, article, err)
+		}
+	})
+	handle("DELETE /api/articles/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := pathId(w, r); ok {
+			writeDeleted(w, api.Articles.Delete(r.Context(), id))
+		}
+	})
+
+	handle("POST /api/fetch", runTrigger("fetch", api.FetchNow))
+	handle("POST /api/post", runTrigger("post", api.PostNow))
+}
+
+func patchArticle(ctx context.Context, articles ArticleStore, id int64, patch articlePatch) error {
+	if _, err := articles.ArticleById(ctx, id); err != nil {
+		return err
+	}
+
+	if patch.Status != nil {
+		if !slices.Contains(articleStatuses, *patch.Status) {
+			return invalidError("invalid status, expected one of " + strings.Join(articleStatuses, ", "))
+		}
+
+		if err := articles.SetStatus(ctx, id, *patch.Status); err != nil {
+			return err
+		}
+	}
+
+	if patch.EditedSummary != nil {
+		return articles.SetEditedSummary(ctx, id, *patch.EditedSummary)
+	}
+
+	return nil
+}
+
+// validateArticle checks an article added by hand. It is published now unless it says otherwise.
+func validateArticle(article *model.Article) error {
+	article.Title = strings.TrimSpace(article.Title)
+	article.Link = strings.TrimSpace(article.Link)
+
+	if article.SourceID <= 0 {
+		return invalidError("invalid source")
+	}
+
+	if article.Title == "" {
+		return invalidError("title is empty")
+	}
+
+	if link, err := url.ParseRequestURI(article.Link); err != nil || link.Host == "" {
+		return invalidError("invalid link")
+	}
+
+	if article.PublishedAt.IsZero() {
+		article.PublishedAt = time.Now()
+	}
+
+	return nil
+}
+
+// validateTopic rejects retention overrides shorter than minDays, since deleted articles would be fetched
+// and posted or queued again. Zero keeps the configured retention.
+func validateTopic(topic model.Topic, minDays int) error {
+	for _, days := range []int{topic.PostedRetentionDays, topic.UnpostedRetentionDays} {
+		if days < 0 || (days != 0 && days < minDays) {
+			return invalidError(fmt.Sprintf("retention must be zero or at least %d days", minDays))
+		}
+	}
+
+	return nil
+}
+
+// runTrigger queues the step in its loop and answers right away, since a fetch or a post may take longer
+// than the client waits. A step already waiting to run is answered with 409.
+func runTrigger(name string, trigger Trigger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !trigger() {
+			http.Error(w, name+" is already queued", http.StatusConflict)
+			return
+		}
+
+		writeJSON(w, http.StatusAccepted, map[string]string{"status": "queued"})
+	}
+}
+
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
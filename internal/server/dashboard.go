@@ -0,0 +1,312 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"embed"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"tg-bot/internal/model"
+	"time"
+)
+
+const (
+	dashboardListSize int64 = 50
+	// staleFetchIntervals is how many fetch intervals may pass without a fetch before a source counts as stale.
+	staleFetchIntervals = 3
+	defaultSourceType   = "rss"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var dashboardTemplates = template.Must(template.New("").Funcs(template.FuncMap{
+	"time": formatTime,
+}).ParseFS(templateFS, "templates/*.html"))
+
+type DashboardArticleProvider interface {
+	QueuedArticles(ctx context.Context, limit int64) ([]model.Article, error)
+	FailedArticles(ctx context.Context, limit int64) ([]model.Article, error)
+	RecentlyPosted(ctx context.Context, limit int64) ([]model.Article, error)
+}
+
+// Dashboard is what the web dashboard shows and manages.
+type Dashboard struct {
+	Sources       SourceStore
+	Topics        TopicStore
+	Articles      DashboardArticleProvider
+	FetchInterval time.Duration
+}
+
+type sourceHealth struct {
+	model.Source
+	Topic  string
+	Health string
+}
+
+type sourceForm struct {
+	Source model.Source
+	Topics []model.Topic
+	Error  string
+}
+
+// HandleDashboard serves the web dashboard under /dashboard. Browsers log in with HTTP basic auth using
+// the admin token as the password.
+func (s *Server) HandleDashboard(token string, dashboard Dashboard) {
+	handle := func(pattern string, handler http.HandlerFunc) {
+		s.Handle(pattern, requirePassword(token, handler))
+	}
+
+	handle("GET /dashboard", func(w http.ResponseWriter, r *http.Request) {
+		data, err := dashboard.overview(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		renderPage(w, http.StatusOK, "dashboard.html", data)
+	})
+	handle("GET /dashboard/sources/new", func(w http.ResponseWriter, r *http.Request) {
+		dashboard.renderSourceForm(w, r, model.Source{Type: defaultSourceType}, "")
+	})
+	handle("POST /dashboard/sources", func(w http.ResponseWriter, r *http.Request) {
+		source, err := sourceFromForm(r, true)
+		if err != nil {
+			dashboard.renderSourceForm(w, r, source, err.Error())
+			return
+		}
+
+		if _, err := dashboard.Sources.Save(r.Context(), source); err != nil {
+			dashboard.renderSourceForm(w, r, source, formError(err))
+			return
+		}
+
+		http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
+	})
+	handle("GET /dashboard/sources/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathId(w, r)
+		if !ok {
+			return
+		}
+
+		source, err := dashboard.Sources.SourceById(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		dashboard.renderSourceForm(w, r, *source, "")
+	})
+	handle("POST /dashboard/sources/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathId(w, r)
+		if !ok {
+			return
+		}
+
+		source, err := sourceFromForm(r, false)
+		source.ID = id
+		if err != nil {
+			dashboard.renderSourceForm(w, r, source, err.Error())
+			return
+		}
+
+		if err := dashboard.Sources.Update(r.Context(), source); err != nil {
+			dashboard.renderSourceForm(w, r, source, formError(err))
+			return
+		}
+
+		http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
+	})
+}
+
+func (d Dashboard) overview(ctx context.Context) (map[string]any, error) {
+	sources, err := d.Sources.Sources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	topics, err := d.Topics.Topics(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	queued, err := d.Articles.QueuedArticles(ctx, dashboardListSize)
+	if err != nil {
+		return nil, err
+	}
+
+	failed, err := d.Articles.FailedArticles(ctx, dashboardListSize)
+	if err != nil {
+		return nil, err
+	}
+
+	posted, err := d.Articles.RecentlyPosted(ctx, dashboardListSize)
+	if err != nil {
+		return nil, err
+	}
+
+	topicNames := make(map[int64]string, len(topics))
+	for _, topic := range topics {
+		topicNames[topic.ID] = topic.Name
+	}
+
+	sourceRows := make([]sourceHealth, 0, len(sources))
+	for _, source := range sources {
+		sourceRows = append(sourceRows, sourceHealth{
+			Source: source,
+			Topic:  topicNames[source.TopicID],
+			Health: d.health(source),
+		})
+	}
+
+	return map[string]any{
+		"Sources": sourceRows,
+		"Topics":  topics,
+		"Queued":  queued,
+		"Failed":  failed,
+		"Posted":  posted,
+	}, nil
+}
+
+// health tells whether the source was fetched without errors lately. Sources not fetched yet are pending.
+func (d Dashboard) health(source model.Source) string {
+	switch {
+	case source.LastFetchedAt.IsZero():
+		return "pending"
+	case source.LastError != "":
+		return "failing"
+	case time.Since(source.LastFetchedAt) > staleFetchIntervals*d.FetchInterval:
+		return "stale"
+	default:
+		return "ok"
+	}
+}
+
+func (d Dashboard) renderSourceForm(w http.ResponseWriter, r *http.Request, source model.Source, formErr string) {
+	topics, err := d.Topics.Topics(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if formErr != "" {
+		status = http.StatusBadRequest
+	}
+
+	renderPage(w, status, "source.html", sourceForm{Source: source, Topics: topics, Error: formErr})
+}
+
+func sourceFromForm(r *http.Request, isNew bool) (model.Source, error) {
+	source := model.Source{
+		Name:    r.PostFormValue("name"),
+		FeedURL: r.PostFormValue("feedURL"),
+		Type:    r.PostFormValue("type"),
+	}
+
+	topicId, err := strconv.ParseInt(r.PostFormValue("topicID"), 10, 64)
+	if err != nil {
+		return source, invalidError("invalid topic")
+	}
+	source.TopicID = topicId
+
+	if priority := r.PostFormValue("priority"); priority != "" {
+		if source.Priority, err = strconv.ParseFloat(priority, 64); err != nil {
+			return source, invalidError("invalid priority")
+		}
+	}
+
+	return source, validateSource(&source, isNew)
+}
+
+// validateSource checks a source from the dashboard or the API and fills in the default type. A new
+// source may leave the priority out to get the default one, an updated source must carry it.
+func validateSource(source *model.Source, isNew bool) error {
+	source.Name = strings.TrimSpace(source.Name)
+	source.FeedURL = strings.TrimSpace(source.FeedURL)
+	source.Type = strings.TrimSpace(source.Type)
+
+	if source.TopicID <= 0 {
+		return invalidError("invalid topic")
+	}
+
+	if source.Name == "" {
+		return invalidError("name is empty")
+	}
+
+	if feedURL, err := url.ParseRequestURI(source.FeedURL); err != nil || feedURL.Host == "" {
+		return invalidError("invalid feed URL")
+	}
+
+	if source.Priority < 0 || (!isNew && source.Priority == 0) {
+		return invalidError("priority must be positive")
+	}
+
+	if source.Type == "" {
+		source.Type = defaultSourceType
+	}
+
+	return nil
+}
+
+// formError explains a failed save to the user without revealing internal errors.
+func formError(err error) string {
+	status, message := apiError(err)
+	if status == http.StatusInternalServerError {
+		log.Printf("[ERROR] Failed to save source from dashboard: %v", err)
+	}
+
+	return message
+}
+
+func renderPage(w http.ResponseWriter, status int, name string, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+
+	if err := dashboardTemplates.ExecuteTemplate(w, name, data); err != nil {
+		log.Printf("[ERROR] Failed to render %s: %v", name, err)
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "—"
+	}
+
+	return t.Format(time.DateTime)
+}
+
+// requirePassword asks the browser for the token through HTTP basic auth. Forms are only accepted from
+// the dashboard itself, since browsers resend basic auth credentials with cross-site requests.
+func requirePassword(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, password, ok := r.BasicAuth()
+		if !ok || subtle.ConstantTimeCompare([]byte(password), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="tg-bot dashboard", charset="UTF-8"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if r.Method == http.MethodPost && !sameOrigin(r) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		origin = r.Header.Get("Referer")
+	}
+
+	originURL, err := url.Parse(origin)
+
+	return err == nil && originURL.Host == r.Host
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"tg-bot/internal/feed"
+	"tg-bot/internal/model"
+)
+
+type FeedArticleProvider interface {
+	PostedByTopicId(ctx context.Context, topicId int64, limit int64) ([]model.Article, error)
+	PostedByChannelId(ctx context.Context, channelId int64, limit int64) ([]model.Article, error)
+}
+
+type TopicFinder interface {
+	TopicById(ctx context.Context, id int64) (*model.Topic, error)
+}
+
+type ChannelFinder interface {
+	ChannelById(ctx context.Context, id int64) (*model.Channel, error)
+}
+
+// HandleFeeds serves Atom feeds of the posted articles of every topic and channel.
+func (s *Server) HandleFeeds(
+	articles FeedArticleProvider,
+	topics TopicFinder,
+	channels ChannelFinder,
+	size int64,
+) {
+	s.HandleFunc("GET /feeds/topics/{id}", func(w http.ResponseWriter, r *http.Request) {
+		topicId, ok := pathId(w, r)
+		if !ok {
+			return
+		}
+
+		topic, err := topics.TopicById(r.Context(), topicId)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		posted, err := articles.PostedByTopicId(r.Context(), topicId, size)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeFeed(w, feed.NewAtom(
+			fmt.Sprintf("urn:tg-bot:topic:%d", topic.ID),
+			topic.Name,
+			topic.Description,
+			requestURL(r),
+			posted,
+		))
+	})
+
+	s.HandleFunc("GET /feeds/channels/{id}", func(w http.ResponseWriter, r *http.Request) {
+		channelId, ok := pathId(w, r)
+		if !ok {
+			return
+		}
+
+		channel, err := channels.ChannelById(r.Context(), channelId)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		posted, err := articles.PostedByChannelId(r.Context(), channelId, size)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeFeed(w, feed.NewAtom(
+			fmt.Sprintf("urn:tg-bot:channel:%d", channel.ID),
+			channel.Name,
+			"",
+			requestURL(r),
+			posted,
+		))
+	})
+}
+
+func writeFeed(w http.ResponseWriter, atom feed.Atom) {
+	body, err := atom.Marshal()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+
+	if _, err := w.Write(body); err != nil {
+		log.Printf("[ERROR] Failed to write feed: %v", err)
+	}
+}
+
+// requestURL rebuilds the URL the client requested, honoring the scheme set by a reverse proxy.
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
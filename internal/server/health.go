@@ -0,0 +1,25 @@
+package server
+
+import (
+	"net/http"
+	"tg-bot/internal/health"
+)
+
+// HandleHealth serves the liveness and readiness reports as JSON, with status 503 when a component fails.
+func (s *Server) HandleHealth(liveness *health.Checker, readiness *health.Checker) {
+	s.HandleFunc("GET /healthz", reportHealth(liveness))
+	s.HandleFunc("GET /readyz", reportHealth(readiness))
+}
+
+func reportHealth(checker *health.Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := checker.Run(r.Context())
+
+		status := http.StatusOK
+		if report.Status != health.StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+
+		writeJSON(w, status, report)
+	}
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/lib/pq"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// invalidError is a request the client can fix. It is answered with its message.
+type invalidError string
+
+func (e invalidError) Error() string { return string(e) }
+
+func pathId(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return 0, false
+	}
+
+	return id, true
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status, message := apiError(err)
+	if status == http.StatusInternalServerError {
+		log.Printf("[ERROR] Failed to handle request: %v", err)
+	}
+
+	http.Error(w, message, status)
+}
+
+func page(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	var (
+		limit  = defaultPageSize
+		offset int64
+		err    error
+	)
+
+	if value := r.URL.Query().Get("limit"); value != "" {
+		if limit, err = strconv.ParseInt(value, 10, 64); err != nil || limit <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return 0, 0, false
+		}
+	}
+
+	if value := r.URL.Query().Get("offset"); value != "" {
+		if offset, err = strconv.ParseInt(value, 10, 64); err != nil || offset < 0 {
+			http.Error(w, "invalid offset", http.StatusBadRequest)
+			return 0, 0, false
+		}
+	}
+
+	return limit, offset, true
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+func writeResult(w http.ResponseWriter, v any, err error) {
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, v)
+}
+
+func writeCreated(w http.ResponseWriter, id int64, err error) {
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]int64{"id": id})
+}
+
+func writeDeleted(w http.ResponseWriter, err error) {
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("[ERROR] Failed to write response: %v", err)
+	}
+}
+
+// apiError maps storage errors to HTTP statuses: missing rows and constraint violations are the client's fault.
+func apiError(err error) (int, string) {
+	var invalid invalidError
+	if errors.As(err, &invalid) {
+		return http.StatusBadRequest, invalid.Error()
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return http.StatusNotFound, "not found"
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		case "23":
+			return http.StatusConflict, pqErr.Message
+		case "22":
+			return http.StatusBadRequest, pqErr.Message
+		}
+	}
+
+	return http.StatusInternalServerError, "internal error"
+}
@@ -0,0 +1,53 @@
+package source
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// OPMLFeed is a feed listed in an OPML file, the usual export format of feed readers.
+type OPMLFeed struct {
+	Title string
+	URL   string
+}
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr"`
+	XMLURL   string        `xml:"xmlUrl,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+type opmlDocument struct {
+	Outlines []opmlOutline `xml:"body>outline"`
+}
+
+// ParseOPML returns the feeds of an OPML file, including those nested in folders.
+func ParseOPML(r io.Reader) ([]OPMLFeed, error) {
+	var doc opmlDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	return collectFeeds(doc.Outlines), nil
+}
+
+func collectFeeds(outlines []opmlOutline) []OPMLFeed {
+	var feeds []OPMLFeed
+
+	for _, outline := range outlines {
+		if url := strings.TrimSpace(outline.XMLURL); url != "" {
+			title := outline.Title
+			if title == "" {
+				title = outline.Text
+			}
+
+			feeds = append(feeds, OPMLFeed{Title: strings.TrimSpace(title), URL: url})
+		}
+
+		feeds = append(feeds, collectFeeds(outline.Outlines)...)
+	}
+
+	return feeds
+}
@@ -3,21 +3,88 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"log"
 	"tg-bot/internal/model"
 	"tg-bot/internal/utils"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/samber/lo"
 )
 
 const (
 	saveArticle          string = "INSERT INTO articles (source_id, title, link, summary, published_at) VALUES ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING"
-	findAllNotPosted     string = "SELECT * FROM articles where posted_at IS NULL AND published_at >= $1::timestamp ORDER BY published_at DESC LIMIT $2"
-	markPosted           string = "UPDATE articles SET posted_at = now() WHERE id = $1"
-	deletePosted         string = "DELETE FROM articles WHERE posted_at IS NOT NULL"
-	minutesCleanInterval int    = 120
+	createArticle        string = "INSERT INTO articles (source_id, title, link, summary, published_at) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	findNotPostedByTopic string = "SELECT a.* FROM articles a JOIN sources s ON s.id = a.source_id " +
+		"WHERE s.topic_id = $1 AND a.posted_at IS NULL AND a.publish_state <> 'dead' AND a.published_at >= $2::timestamp " +
+		"ORDER BY a.published_at DESC LIMIT $3"
+	reviewedByTopic string = "SELECT a.* FROM articles a JOIN sources s ON s.id = a.source_id " +
+		"WHERE s.topic_id = $1 AND a.posted_at IS NULL AND a.publish_state <> 'dead' " +
+		"AND a.status IN ('pending_review', 'approved') ORDER BY a.published_at DESC"
+	postedByTopicId string = "SELECT a.* FROM articles a JOIN sources s ON s.id = a.source_id " +
+		"WHERE s.topic_id = $1 AND a.posted_at IS NOT NULL ORDER BY a.posted_at DESC LIMIT $2"
+	postedByChannelId string = "SELECT a.* FROM articles a JOIN article_posts p ON p.article_id = a.id " +
+		"WHERE p.channel_id = $1 AND p.state = 'posted' ORDER BY p.posted_at DESC LIMIT $2"
+	queuedArticles string = "SELECT * FROM articles WHERE posted_at IS NULL AND publish_state NOT IN ('failed', 'dead') " +
+		"ORDER BY published_at DESC LIMIT $1"
+	countQueued    string = "SELECT count(*) FROM articles WHERE posted_at IS NULL AND publish_state <> 'dead'"
+	failedArticles string = "SELECT * FROM articles WHERE posted_at IS NULL AND publish_state IN ('failed', 'dead') " +
+		"ORDER BY next_attempt_at DESC NULLS LAST LIMIT $1"
+	recentlyPosted string = "SELECT * FROM articles WHERE posted_at IS NOT NULL ORDER BY posted_at DESC LIMIT $1"
+	createdSince   string = "SELECT * FROM articles WHERE created_at >= $1::timestamp ORDER BY created_at"
+	selectArticles string = "SELECT * FROM articles ORDER BY published_at DESC LIMIT $1 OFFSET $2"
+	deleteArticle  string = "DELETE FROM articles WHERE id = $1"
+	markPosted     string = "UPDATE articles SET posted_at = now(), publish_state = 'posted', publish_error = '', " +
+		"next_attempt_at = NULL WHERE id = $1"
+	// The topic's retention, or the configured one in $1 and $2 days. Posted articles expire after posted_at,
+	// unposted ones after published_at, which is stored in UTC and compared with $3.
+	postedExpired = "a.posted_at IS NOT NULL AND " +
+		"a.posted_at < now() - make_interval(days => COALESCE(NULLIF(t.posted_retention_days, 0), $1))"
+	unpostedExpired = "a.posted_at IS NULL AND " +
+		"a.published_at < $3::timestamp - make_interval(days => COALESCE(NULLIF(t.unposted_retention_days, 0), $2))"
+	retentionReport string = "SELECT t.id AS topic_id, t.name AS topic_name, " +
+		"COALESCE(NULLIF(t.posted_retention_days, 0), $1) AS posted_days, " +
+		"COALESCE(NULLIF(t.unposted_retention_days, 0), $2) AS unposted_days, " +
+		"count(a.id) FILTER (WHERE " + postedExpired + ") AS expired_posted, " +
+		"count(a.id) FILTER (WHERE " + unpostedExpired + ") AS expired_stale " +
+		"FROM topics t LEFT JOIN sources s ON s.topic_id = t.id LEFT JOIN articles a ON a.source_id = s.id " +
+		"GROUP BY t.id, t.name ORDER BY t.id"
+	deleteExpired string = "DELETE FROM articles a USING sources s JOIN topics t ON t.id = s.topic_id " +
+		"WHERE a.source_id = s.id AND ((" + postedExpired + ") OR (" + unpostedExpired + "))"
+	findArticleById string = "SELECT * FROM articles WHERE id = $1"
+	markDelivered   string = "INSERT INTO article_posts (article_id, channel_id, chat_id, message_id, summary) " +
+		"VALUES ($1, NULLIF($2, 0), $3, $4, $5) ON CONFLICT DO NOTHING"
+	deliveredArticleIds string = "SELECT article_id FROM article_posts WHERE channel_id = $1 AND article_id = ANY($2)"
+	deliveredChannelIds string = "SELECT channel_id FROM article_posts WHERE article_id = $1 AND channel_id IS NOT NULL"
+	selectPosts         string = "SELECT id, article_id, COALESCE(channel_id, 0) AS channel_id, chat_id, message_id, " +
+		"summary, state, likes, dislikes, posted_at FROM article_posts "
+	postsByMessage      string = selectPosts + "WHERE chat_id = $1 AND message_id = $2"
+	postsByArticleId    string = selectPosts + "WHERE article_id = $1 ORDER BY posted_at"
+	setPostReactions    string = "UPDATE article_posts SET likes = $2, dislikes = $3 WHERE id = $1"
+	countPostsByMessage string = "SELECT count(*) FROM article_posts WHERE chat_id = $1 AND message_id = $2"
+	deletePosts         string = "DELETE FROM article_posts WHERE article_id = $1"
+	deletePost          string = "DELETE FROM article_posts WHERE id = $1"
+	resetPosted         string = "UPDATE articles SET posted_at = NULL, publish_state = 'queued', publish_error = '', " +
+		"publish_attempts = 0, next_attempt_at = NULL WHERE id = $1"
+	claimDelivery string = "INSERT INTO article_posts (article_id, channel_id, chat_id, summary, state) " +
+		"VALUES ($1, NULLIF($2, 0), $3, $4, 'sending') ON CONFLICT DO NOTHING RETURNING id"
+	confirmDelivery      string = "UPDATE article_posts SET state = 'posted', message_id = $2, posted_at = now() WHERE id = $1"
+	cancelDelivery       string = "DELETE FROM article_posts WHERE id = $1 AND state = 'sending'"
+	unconfirmDeliveries  string = "UPDATE article_posts SET state = 'unconfirmed' WHERE state = 'sending'"
+	setPublishState      string = "UPDATE articles SET publish_state = $2, publish_error = $3 WHERE id = $1"
+	recordPublishFailure string = "UPDATE articles SET publish_state = $2, publish_error = $3, " +
+		"publish_attempts = publish_attempts + 1, next_attempt_at = $4 WHERE id = $1"
+	replacePublishState string = "UPDATE articles SET publish_state = $2 WHERE publish_state = $1 AND posted_at IS NULL"
+	findByPublishState  string = "SELECT * FROM articles WHERE publish_state = $1 AND posted_at IS NULL"
+	markPendingReview   string = "UPDATE articles SET status = 'pending_review', review_message_id = $2 WHERE id = $1"
+	setArticleStatus    string = "UPDATE articles SET status = $2 WHERE id = $1"
+	setGeneratedSummary string = "UPDATE articles SET generated_summary = $2, summary_model = $3, summary_prompt = $4, " +
+		"edited_summary = '' WHERE id = $1"
+	setEditedSummary    string = "UPDATE articles SET edited_summary = $2 WHERE id = $1"
+	findByReviewMessage string = "SELECT * FROM articles WHERE review_message_id = $1 AND review_message_id <> 0"
+	countDeliveredSince string = "SELECT count(*) FROM article_posts WHERE channel_id = $1 AND posted_at >= $2::timestamp"
 )
 
 type ArticlePostgresStorage struct {
@@ -49,7 +116,36 @@ func (a *ArticlePostgresStorage) Save(ctx context.Context, article model.Article
 	return nil
 }
 
-func (a *ArticlePostgresStorage) FindAllNotPosted(ctx context.Context, since time.Time, limit int64) ([]model.Article, error) {
+// Create stores an article added by hand and returns its ID. Unlike Save, it fails on an article whose
+// link is already stored.
+func (a *ArticlePostgresStorage) Create(ctx context.Context, article model.Article) (int64, error) {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var id int64
+	if err := conn.GetContext(ctx, &id, createArticle,
+		article.SourceID,
+		article.Title,
+		article.Link,
+		article.Summary,
+		article.PublishedAt.UTC(),
+	); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// NotPostedByTopicId returns the unposted articles of the topic published since the given time, newest first.
+func (a *ArticlePostgresStorage) NotPostedByTopicId(
+	ctx context.Context,
+	topicId int64,
+	since time.Time,
+	limit int64,
+) ([]model.Article, error) {
 	conn, err := a.getConnection(ctx)
 	if err != nil {
 		return nil, err
@@ -57,23 +153,105 @@ func (a *ArticlePostgresStorage) FindAllNotPosted(ctx context.Context, since tim
 	defer utils.HandleCloseDbConnection(conn)
 
 	var articles []dbArticle
-	if err := conn.SelectContext(ctx, &articles, findAllNotPosted, since.UTC().Format(time.RFC3339), limit); err != nil {
+	if err := conn.SelectContext(ctx, &articles, findNotPostedByTopic,
+		topicId,
+		since.UTC().Format(time.RFC3339),
+		limit,
+	); err != nil {
 		return nil, err
 	}
 
 	return lo.Map(articles, func(article dbArticle, _ int) model.Article {
-		return model.Article{
-			ID:          article.ID,
-			SourceID:    article.SourceID,
-			Title:       article.Title,
-			Link:        article.Link,
-			Summary:     article.Summary,
-			PublishedAt: article.PublishedAt,
-			CreatedAt:   article.CreatedAt,
-		}
+		return article.toModel()
 	}), nil
 }
 
+// ReviewedByTopicId returns the unposted articles of the topic that await review or were approved,
+// however long ago they were published.
+func (a *ArticlePostgresStorage) ReviewedByTopicId(ctx context.Context, topicId int64) ([]model.Article, error) {
+	return a.selectArticles(ctx, reviewedByTopic, topicId)
+}
+
+// PostedByTopicId returns the latest posted articles of the topic.
+func (a *ArticlePostgresStorage) PostedByTopicId(ctx context.Context, topicId int64, limit int64) ([]model.Article, error) {
+	return a.selectArticles(ctx, postedByTopicId, topicId, limit)
+}
+
+// PostedByChannelId returns the latest articles delivered to the channel.
+func (a *ArticlePostgresStorage) PostedByChannelId(ctx context.Context, channelId int64, limit int64) ([]model.Article, error) {
+	return a.selectArticles(ctx, postedByChannelId, channelId, limit)
+}
+
+// QueuedArticles returns the newest articles waiting to be posted.
+func (a *ArticlePostgresStorage) QueuedArticles(ctx context.Context, limit int64) ([]model.Article, error) {
+	return a.selectArticles(ctx, queuedArticles, limit)
+}
+
+// CountQueued returns how many articles wait to be posted, not counting those given up on.
+func (a *ArticlePostgresStorage) CountQueued(ctx context.Context) (int64, error) {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var count int64
+	if err := conn.GetContext(ctx, &count, countQueued); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// FailedArticles returns the articles whose last publish attempt failed, including those given up on.
+func (a *ArticlePostgresStorage) FailedArticles(ctx context.Context, limit int64) ([]model.Article, error) {
+	return a.selectArticles(ctx, failedArticles, limit)
+}
+
+// RecentlyPosted returns the latest posted articles.
+func (a *ArticlePostgresStorage) RecentlyPosted(ctx context.Context, limit int64) ([]model.Article, error) {
+	return a.selectArticles(ctx, recentlyPosted, limit)
+}
+
+// ArticlesCreatedSince returns the articles saved since the time, oldest first.
+func (a *ArticlePostgresStorage) ArticlesCreatedSince(ctx context.Context, since time.Time) ([]model.Article, error) {
+	return a.selectArticles(ctx, createdSince, since.UTC().Format(time.RFC3339))
+}
+
+// Articles returns a page of all articles, newest first.
+func (a *ArticlePostgresStorage) Articles(ctx context.Context, limit int64, offset int64) ([]model.Article, error) {
+	return a.selectArticles(ctx, selectArticles, limit, offset)
+}
+
+func (a *ArticlePostgresStorage) Delete(ctx context.Context, id int64) error {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err := conn.ExecContext(ctx, deleteArticle, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a *ArticlePostgresStorage) ArticleById(ctx context.Context, id int64) (*model.Article, error) {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var article dbArticle
+	if err := conn.GetContext(ctx, &article, findArticleById, id); err != nil {
+		return nil, err
+	}
+
+	return lo.ToPtr(article.toModel()), nil
+}
+
 func (a *ArticlePostgresStorage) MarkPostedById(ctx context.Context, id int64) error {
 	conn, err := a.getConnection(ctx)
 	if err != nil {
@@ -88,55 +266,516 @@ func (a *ArticlePostgresStorage) MarkPostedById(ctx context.Context, id int64) e
 	return nil
 }
 
-func (a *ArticlePostgresStorage) StartCleaner(ctx context.Context) error {
-	ticker := time.NewTicker(time.Minute * time.Duration(minutesCleanInterval))
-	defer ticker.Stop()
+func (a *ArticlePostgresStorage) MarkDelivered(ctx context.Context, post model.ArticlePost) error {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err := conn.ExecContext(ctx,
+		markDelivered,
+		post.ArticleID,
+		post.ChannelID,
+		post.ChatID,
+		post.MessageID,
+		post.Summary,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PostsByMessage returns the posts of a message: one for a single article, one per article for a digest.
+func (a *ArticlePostgresStorage) PostsByMessage(ctx context.Context, chatId int64, messageId int) ([]model.ArticlePost, error) {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var posts []dbArticlePost
+	if err := conn.SelectContext(ctx, &posts, postsByMessage, chatId, messageId); err != nil {
+		return nil, err
+	}
+
+	return lo.Map(posts, func(post dbArticlePost, _ int) model.ArticlePost {
+		return model.ArticlePost(post)
+	}), nil
+}
+
+func (a *ArticlePostgresStorage) PostsByArticleId(ctx context.Context, articleId int64) ([]model.ArticlePost, error) {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var posts []dbArticlePost
+	if err := conn.SelectContext(ctx, &posts, postsByArticleId, articleId); err != nil {
+		return nil, err
+	}
+
+	return lo.Map(posts, func(post dbArticlePost, _ int) model.ArticlePost {
+		return model.ArticlePost(post)
+	}), nil
+}
+
+func (a *ArticlePostgresStorage) CountPostsByMessage(ctx context.Context, chatId int64, messageId int) (int, error) {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var count int
+	if err := conn.GetContext(ctx, &count, countPostsByMessage, chatId, messageId); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
+// ResetPosted forgets all deliveries of the article and clears its posted_at.
+func (a *ArticlePostgresStorage) ResetPosted(ctx context.Context, id int64) error {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
 
-		case <-ticker.C:
-			err := a.deletePosted(ctx)
-			if err != nil {
-				return err
-			}
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, deletePosts, id); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, resetPosted, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeletePost forgets one delivery of an article, e.g. after its message was deleted from the chat.
+func (a *ArticlePostgresStorage) DeletePost(ctx context.Context, id int64) error {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err := conn.ExecContext(ctx, deletePost, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ClaimDelivery records that the article is being sent to the chat before the message goes out.
+// It reports false when the article has already been delivered to the channel or is being sent there.
+func (a *ArticlePostgresStorage) ClaimDelivery(ctx context.Context, post model.ArticlePost) (int64, bool, error) {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var id int64
+	if err := conn.GetContext(ctx, &id, claimDelivery,
+		post.ArticleID,
+		post.ChannelID,
+		post.ChatID,
+		post.Summary,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
 		}
+
+		return 0, false, err
 	}
+
+	return id, true, nil
 }
 
-func (a *ArticlePostgresStorage) getConnection(ctx context.Context) (*sqlx.Conn, error) {
-	conn, err := a.db.Connx(ctx)
+func (a *ArticlePostgresStorage) ConfirmDelivery(ctx context.Context, id int64, messageId int) error {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err := conn.ExecContext(ctx, confirmDelivery, id, messageId); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CancelDelivery drops a claimed delivery whose message was not sent.
+func (a *ArticlePostgresStorage) CancelDelivery(ctx context.Context, id int64) error {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err := conn.ExecContext(ctx, cancelDelivery, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UnconfirmDeliveries marks deliveries left in the sending state as unconfirmed and returns their number.
+func (a *ArticlePostgresStorage) UnconfirmDeliveries(ctx context.Context) (int64, error) {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	result, err := conn.ExecContext(ctx, unconfirmDeliveries)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (a *ArticlePostgresStorage) SetPublishState(ctx context.Context, id int64, state string, publishError string) error {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err := conn.ExecContext(ctx, setPublishState, id, state, publishError); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RecordPublishFailure counts a failed attempt to publish the article and schedules the next one.
+func (a *ArticlePostgresStorage) RecordPublishFailure(
+	ctx context.Context,
+	id int64,
+	state string,
+	publishError string,
+	nextAttemptAt time.Time,
+) error {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err := conn.ExecContext(ctx, recordPublishFailure, id, state, publishError, nextAttemptAt.UTC()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ReplacePublishState moves every unposted article from one publish state to another.
+func (a *ArticlePostgresStorage) ReplacePublishState(ctx context.Context, from string, to string) error {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err := conn.ExecContext(ctx, replacePublishState, from, to); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a *ArticlePostgresStorage) ArticlesByPublishState(ctx context.Context, state string) ([]model.Article, error) {
+	conn, err := a.getConnection(ctx)
 	if err != nil {
-		log.Printf("[ERROR] Failed to get connection to database: %v", err)
 		return nil, err
 	}
+	defer utils.HandleCloseDbConnection(conn)
 
-	return conn, nil
+	var articles []dbArticle
+	if err := conn.SelectContext(ctx, &articles, findByPublishState, state); err != nil {
+		return nil, err
+	}
+
+	return lo.Map(articles, func(article dbArticle, _ int) model.Article {
+		return article.toModel()
+	}), nil
+}
+
+func (a *ArticlePostgresStorage) SetPostReactions(ctx context.Context, postId int64, likes int, dislikes int) error {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err := conn.ExecContext(ctx, setPostReactions, postId, likes, dislikes); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a *ArticlePostgresStorage) DeliveredArticleIds(ctx context.Context, channelId int64, articleIds []int64) ([]int64, error) {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var ids []int64
+	if err := conn.SelectContext(ctx, &ids, deliveredArticleIds, channelId, pq.Array(articleIds)); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (a *ArticlePostgresStorage) DeliveredChannelIds(ctx context.Context, articleId int64) ([]int64, error) {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var ids []int64
+	if err := conn.SelectContext(ctx, &ids, deliveredChannelIds, articleId); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (a *ArticlePostgresStorage) CountDeliveredSince(ctx context.Context, channelId int64, since time.Time) (int, error) {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var count int
+	if err := conn.GetContext(ctx, &count, countDeliveredSince, channelId, since.UTC().Format(time.RFC3339)); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (a *ArticlePostgresStorage) MarkPendingReview(ctx context.Context, id int64, messageId int) error {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err := conn.ExecContext(ctx, markPendingReview, id, messageId); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a *ArticlePostgresStorage) SetStatus(ctx context.Context, id int64, status string) error {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err := conn.ExecContext(ctx, setArticleStatus, id, status); err != nil {
+		return err
+	}
+
+	return nil
 }
 
-func (a *ArticlePostgresStorage) deletePosted(ctx context.Context) error {
+func (a *ArticlePostgresStorage) SetGeneratedSummary(ctx context.Context, id int64, summary model.Summary) error {
 	conn, err := a.getConnection(ctx)
 	if err != nil {
 		return err
 	}
 	defer utils.HandleCloseDbConnection(conn)
 
-	if _, err = conn.ExecContext(ctx, deletePosted); err != nil {
+	if _, err := conn.ExecContext(ctx, setGeneratedSummary, id, summary.Text, summary.Model, summary.Prompt); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+func (a *ArticlePostgresStorage) SetEditedSummary(ctx context.Context, id int64, summary string) error {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err := conn.ExecContext(ctx, setEditedSummary, id, summary); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a *ArticlePostgresStorage) ArticleByReviewMessage(ctx context.Context, messageId int) (*model.Article, error) {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var article dbArticle
+	if err := conn.GetContext(ctx, &article, findByReviewMessage, messageId); err != nil {
+		return nil, err
+	}
+
+	return lo.ToPtr(article.toModel()), nil
+}
+
+// RetentionReport counts the articles of every topic that are past their retention, without deleting them.
+func (a *ArticlePostgresStorage) RetentionReport(
+	ctx context.Context,
+	postedDays int,
+	unpostedDays int,
+) ([]model.RetentionReport, error) {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var reports []dbRetentionReport
+	if err := conn.SelectContext(ctx, &reports, retentionReport,
+		postedDays, unpostedDays, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return nil, err
+	}
+
+	return lo.Map(reports, func(report dbRetentionReport, _ int) model.RetentionReport {
+		return model.RetentionReport(report)
+	}), nil
+}
+
+// DeleteExpired deletes the articles past the retention of their topic and returns how many were deleted.
+func (a *ArticlePostgresStorage) DeleteExpired(ctx context.Context, postedDays int, unpostedDays int) (int64, error) {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	result, err := conn.ExecContext(ctx, deleteExpired,
+		postedDays, unpostedDays, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (a *ArticlePostgresStorage) selectArticles(ctx context.Context, query string, args ...any) ([]model.Article, error) {
+	conn, err := a.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var articles []dbArticle
+	if err := conn.SelectContext(ctx, &articles, query, args...); err != nil {
+		return nil, err
+	}
+
+	return lo.Map(articles, func(article dbArticle, _ int) model.Article {
+		return article.toModel()
+	}), nil
+}
+
+func (a *ArticlePostgresStorage) getConnection(ctx context.Context) (*sqlx.Conn, error) {
+	conn, err := a.db.Connx(ctx)
+	if err != nil {
+		log.Printf("[ERROR] Failed to get connection to database: %v", err)
+		return nil, err
+	}
+
+	return conn, nil
+}
+
 type dbArticle struct {
-	ID          int64        `db:"id"`
-	SourceID    int64        `db:"source_id"`
-	Title       string       `db:"title"`
-	Link        string       `db:"link"`
-	Summary     string       `db:"summary"`
-	PublishedAt time.Time    `db:"published_at"`
-	CreatedAt   time.Time    `db:"created_at"`
-	PostedAt    sql.NullTime `db:"posted_at"`
+	ID               int64        `db:"id"`
+	SourceID         int64        `db:"source_id"`
+	Title            string       `db:"title"`
+	Link             string       `db:"link"`
+	Summary          string       `db:"summary"`
+	Status           string       `db:"status"`
+	GeneratedSummary string       `db:"generated_summary"`
+	EditedSummary    string       `db:"edited_summary"`
+	SummaryModel     string       `db:"summary_model"`
+	SummaryPrompt    string       `db:"summary_prompt"`
+	ReviewMessageID  int          `db:"review_message_id"`
+	PublishState     string       `db:"publish_state"`
+	PublishError     string       `db:"publish_error"`
+	PublishAttempts  int          `db:"publish_attempts"`
+	NextAttemptAt    sql.NullTime `db:"next_attempt_at"`
+	PublishedAt      time.Time    `db:"published_at"`
+	CreatedAt        time.Time    `db:"created_at"`
+	PostedAt         sql.NullTime `db:"posted_at"`
+}
+
+func (a dbArticle) toModel() model.Article {
+	return model.Article{
+		ID:               a.ID,
+		SourceID:         a.SourceID,
+		Title:            a.Title,
+		Link:             a.Link,
+		Summary:          a.Summary,
+		Status:           a.Status,
+		GeneratedSummary: a.GeneratedSummary,
+		EditedSummary:    a.EditedSummary,
+		SummaryModel:     a.SummaryModel,
+		SummaryPrompt:    a.SummaryPrompt,
+		ReviewMessageID:  a.ReviewMessageID,
+		PublishState:     a.PublishState,
+		PublishError:     a.PublishError,
+		PublishAttempts:  a.PublishAttempts,
+		NextAttemptAt:    a.NextAttemptAt.Time,
+		PublishedAt:      a.PublishedAt,
+		CreatedAt:        a.CreatedAt,
+		PostedAt:         a.PostedAt.Time,
+	}
+}
+
+type dbArticlePost struct {
+	ID        int64     `db:"id"`
+	ArticleID int64     `db:"article_id"`
+	ChannelID int64     `db:"channel_id"`
+	ChatID    int64     `db:"chat_id"`
+	MessageID int       `db:"message_id"`
+	Summary   string    `db:"summary"`
+	State     string    `db:"state"`
+	Likes     int       `db:"likes"`
+	Dislikes  int       `db:"dislikes"`
+	PostedAt  time.Time `db:"posted_at"`
+}
+
+type dbRetentionReport struct {
+	TopicID       int64  `db:"topic_id"`
+	TopicName     string `db:"topic_name"`
+	PostedDays    int    `db:"posted_days"`
+	UnpostedDays  int    `db:"unposted_days"`
+	ExpiredPosted int64  `db:"expired_posted"`
+	ExpiredStale  int64  `db:"expired_stale"`
 }
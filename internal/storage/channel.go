@@ -0,0 +1,266 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+	"log"
+	"tg-bot/internal/model"
+	"tg-bot/internal/utils"
+	"time"
+)
+
+const (
+	selectAllChannels string = "SELECT * FROM channels"
+	findChannelById   string = "SELECT * FROM channels WHERE id = $1"
+	saveChannel       string = "INSERT INTO channels (chat_id, name) VALUES ($1, $2) RETURNING id"
+	deleteChannel     string = "DELETE FROM channels WHERE id = $1"
+	channelsByTopicId string = "SELECT c.* FROM channels c " +
+		"JOIN topic_channels tc ON tc.channel_id = c.id WHERE tc.topic_id = $1"
+	linkTopicChannel   string = "INSERT INTO topic_channels (topic_id, channel_id) VALUES ($1, $2) ON CONFLICT DO NOTHING"
+	unlinkTopicChannel string = "DELETE FROM topic_channels WHERE topic_id = $1 AND channel_id = $2"
+	setChannelSchedule string = "UPDATE channels SET timezone = $2, schedule = $3, quiet_hours = $4, " +
+		"max_posts_per_hour = $5 WHERE id = $1"
+	setChannelDigest string = "UPDATE channels SET digest_mode = $2, digest_time = $3, digest_weekday = $4, " +
+		"digest_size = $5, digest_narrative = $6 WHERE id = $1"
+	setLastDigestAt     string = "UPDATE channels SET last_digest_at = $2 WHERE id = $1"
+	topicIdsByChannelId string = "SELECT topic_id FROM topic_channels WHERE channel_id = $1"
+)
+
+type ChannelPostgresStorage struct {
+	db *sqlx.DB
+}
+
+func NewChannelStorage(db *sqlx.DB) *ChannelPostgresStorage {
+	return &ChannelPostgresStorage{db: db}
+}
+
+func (c *ChannelPostgresStorage) Channels(ctx context.Context) ([]model.Channel, error) {
+	conn, err := c.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var channels []dbChannel
+	if err := conn.SelectContext(ctx, &channels, selectAllChannels); err != nil {
+		return nil, err
+	}
+
+	return lo.Map(channels, func(channel dbChannel, _ int) model.Channel {
+		return channel.toModel()
+	}), nil
+}
+
+func (c *ChannelPostgresStorage) ChannelById(ctx context.Context, id int64) (*model.Channel, error) {
+	conn, err := c.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var channel dbChannel
+	if err := conn.GetContext(ctx, &channel, findChannelById, id); err != nil {
+		return nil, err
+	}
+
+	return lo.ToPtr(channel.toModel()), nil
+}
+
+func (c *ChannelPostgresStorage) ChannelsByTopicId(ctx context.Context, topicId int64) ([]model.Channel, error) {
+	conn, err := c.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var channels []dbChannel
+	if err := conn.SelectContext(ctx, &channels, channelsByTopicId, topicId); err != nil {
+		return nil, err
+	}
+
+	return lo.Map(channels, func(channel dbChannel, _ int) model.Channel {
+		return channel.toModel()
+	}), nil
+}
+
+func (c *ChannelPostgresStorage) Save(ctx context.Context, channel model.Channel) (int64, error) {
+	conn, err := c.getConnection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var id int64
+
+	row := conn.QueryRowxContext(ctx, saveChannel, channel.ChatID, channel.Name)
+
+	if err := row.Err(); err != nil {
+		return 0, err
+	}
+
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (c *ChannelPostgresStorage) Delete(ctx context.Context, id int64) error {
+	conn, err := c.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err = conn.ExecContext(ctx, deleteChannel, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ChannelPostgresStorage) LinkTopic(ctx context.Context, topicId int64, channelId int64) error {
+	conn, err := c.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err = conn.ExecContext(ctx, linkTopicChannel, topicId, channelId); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ChannelPostgresStorage) UnlinkTopic(ctx context.Context, topicId int64, channelId int64) error {
+	conn, err := c.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err = conn.ExecContext(ctx, unlinkTopicChannel, topicId, channelId); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ChannelPostgresStorage) SetSchedule(ctx context.Context, channel model.Channel) error {
+	conn, err := c.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err = conn.ExecContext(ctx, setChannelSchedule,
+		channel.ID,
+		channel.Timezone,
+		channel.Schedule,
+		channel.QuietHours,
+		channel.MaxPostsPerHour,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ChannelPostgresStorage) SetDigest(ctx context.Context, channel model.Channel) error {
+	conn, err := c.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err = conn.ExecContext(ctx, setChannelDigest,
+		channel.ID,
+		channel.DigestMode,
+		channel.DigestTime,
+		channel.DigestWeekday,
+		channel.DigestSize,
+		channel.DigestNarrative,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ChannelPostgresStorage) SetLastDigestAt(ctx context.Context, id int64, at time.Time) error {
+	conn, err := c.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err = conn.ExecContext(ctx, setLastDigestAt, id, at.UTC()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ChannelPostgresStorage) TopicIdsByChannelId(ctx context.Context, channelId int64) ([]int64, error) {
+	conn, err := c.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var topicIds []int64
+	if err := conn.SelectContext(ctx, &topicIds, topicIdsByChannelId, channelId); err != nil {
+		return nil, err
+	}
+
+	return topicIds, nil
+}
+
+func (c *ChannelPostgresStorage) getConnection(ctx context.Context) (*sqlx.Conn, error) {
+	conn, err := c.db.Connx(ctx)
+	if err != nil {
+		log.Printf("[ERROR] Failed to get connection to database: %v", err)
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+type dbChannel struct {
+	ID              int64        `db:"id"`
+	ChatID          int64        `db:"chat_id"`
+	Name            string       `db:"name"`
+	Timezone        string       `db:"timezone"`
+	Schedule        string       `db:"schedule"`
+	QuietHours      string       `db:"quiet_hours"`
+	MaxPostsPerHour int          `db:"max_posts_per_hour"`
+	DigestMode      string       `db:"digest_mode"`
+	DigestTime      string       `db:"digest_time"`
+	DigestWeekday   int          `db:"digest_weekday"`
+	DigestSize      int          `db:"digest_size"`
+	DigestNarrative bool         `db:"digest_narrative"`
+	LastDigestAt    sql.NullTime `db:"last_digest_at"`
+	CreatedAt       time.Time    `db:"created_at"`
+}
+
+func (c dbChannel) toModel() model.Channel {
+	return model.Channel{
+		ID:              c.ID,
+		ChatID:          c.ChatID,
+		Name:            c.Name,
+		Timezone:        c.Timezone,
+		Schedule:        c.Schedule,
+		QuietHours:      c.QuietHours,
+		MaxPostsPerHour: c.MaxPostsPerHour,
+		DigestMode:      c.DigestMode,
+		DigestTime:      c.DigestTime,
+		DigestWeekday:   time.Weekday(c.DigestWeekday),
+		DigestSize:      c.DigestSize,
+		DigestNarrative: c.DigestNarrative,
+		LastDigestAt:    c.LastDigestAt.Time,
+		CreatedAt:       c.CreatedAt,
+	}
+}
@@ -0,0 +1,283 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+	"log"
+	"tg-bot/internal/model"
+	"tg-bot/internal/utils"
+	"time"
+)
+
+const (
+	selectAllDestinations string = "SELECT * FROM destinations ORDER BY topic_id, id"
+	destinationsByTopicId string = "SELECT * FROM destinations WHERE topic_id = $1"
+	saveDestination       string = "INSERT INTO destinations (topic_id, type, url, token, room, account) " +
+		"VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+	deleteDestination string = "DELETE FROM destinations WHERE id = $1"
+	queueCrosspost    string = "INSERT INTO destination_posts (destination_id, article_id, summary, state) " +
+		"VALUES ($1, $2, $3, 'pending') ON CONFLICT DO NOTHING"
+	dueCrossposts string = "SELECT destination_id, article_id, summary, state, attempts, next_attempt_at, last_error, " +
+		"posted_at FROM destination_posts WHERE state IN ('pending', 'failed') " +
+		"AND (next_attempt_at IS NULL OR next_attempt_at <= $1::timestamp) ORDER BY article_id LIMIT $2"
+	setCrosspostState string = "UPDATE destination_posts SET state = $3 WHERE destination_id = $1 AND article_id = $2"
+	confirmCrosspost  string = "UPDATE destination_posts SET state = 'posted', last_error = '', posted_at = now() " +
+		"WHERE destination_id = $1 AND article_id = $2"
+	recordCrosspostFailure string = "UPDATE destination_posts SET state = $3, last_error = $4, " +
+		"attempts = attempts + 1, next_attempt_at = $5 WHERE destination_id = $1 AND article_id = $2"
+	unconfirmCrossposts string = "UPDATE destination_posts SET state = 'unconfirmed' WHERE state = 'sending'"
+	forgetCrossposts    string = "DELETE FROM destination_posts WHERE article_id = $1 AND state <> 'sending'"
+)
+
+type DestinationPostgresStorage struct {
+	db *sqlx.DB
+}
+
+func NewDestinationStorage(db *sqlx.DB) *DestinationPostgresStorage {
+	return &DestinationPostgresStorage{db: db}
+}
+
+func (d *DestinationPostgresStorage) Destinations(ctx context.Context) ([]model.Destination, error) {
+	return d.selectDestinations(ctx, selectAllDestinations)
+}
+
+func (d *DestinationPostgresStorage) DestinationsByTopicId(ctx context.Context, topicId int64) ([]model.Destination, error) {
+	return d.selectDestinations(ctx, destinationsByTopicId, topicId)
+}
+
+func (d *DestinationPostgresStorage) Save(ctx context.Context, destination model.Destination) (int64, error) {
+	conn, err := d.getConnection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var id int64
+
+	row := conn.QueryRowxContext(ctx, saveDestination,
+		destination.TopicID,
+		destination.Type,
+		destination.URL,
+		destination.Token,
+		destination.Room,
+		destination.Account,
+	)
+
+	if err := row.Err(); err != nil {
+		return 0, err
+	}
+
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (d *DestinationPostgresStorage) Delete(ctx context.Context, id int64) error {
+	conn, err := d.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err = conn.ExecContext(ctx, deleteDestination, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// QueueCrosspost records that the article is to be cross-posted to the destination with the summary.
+// An article already queued for the destination is left as it is.
+func (d *DestinationPostgresStorage) QueueCrosspost(ctx context.Context, crosspost model.Crosspost) error {
+	conn, err := d.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err = conn.ExecContext(ctx, queueCrosspost,
+		crosspost.DestinationID,
+		crosspost.ArticleID,
+		crosspost.Summary,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DueCrossposts returns the pending cross-posts and the failed ones whose retry is due.
+func (d *DestinationPostgresStorage) DueCrossposts(ctx context.Context, now time.Time, limit int64) ([]model.Crosspost, error) {
+	conn, err := d.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var crossposts []dbCrosspost
+	if err := conn.SelectContext(ctx, &crossposts, dueCrossposts, now.UTC().Format(time.RFC3339), limit); err != nil {
+		return nil, err
+	}
+
+	return lo.Map(crossposts, func(crosspost dbCrosspost, _ int) model.Crosspost {
+		return crosspost.toModel()
+	}), nil
+}
+
+func (d *DestinationPostgresStorage) SetCrosspostState(
+	ctx context.Context,
+	destinationId int64,
+	articleId int64,
+	state string,
+) error {
+	conn, err := d.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err = conn.ExecContext(ctx, setCrosspostState, destinationId, articleId, state); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *DestinationPostgresStorage) ConfirmCrosspost(ctx context.Context, destinationId int64, articleId int64) error {
+	conn, err := d.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err = conn.ExecContext(ctx, confirmCrosspost, destinationId, articleId); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *DestinationPostgresStorage) RecordCrosspostFailure(
+	ctx context.Context,
+	destinationId int64,
+	articleId int64,
+	state string,
+	crosspostError string,
+	nextAttemptAt time.Time,
+) error {
+	conn, err := d.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err = conn.ExecContext(ctx, recordCrosspostFailure,
+		destinationId,
+		articleId,
+		state,
+		crosspostError,
+		nextAttemptAt.UTC(),
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UnconfirmCrossposts marks the cross-posts interrupted while sending, so that they are not sent twice.
+func (d *DestinationPostgresStorage) UnconfirmCrossposts(ctx context.Context) (int64, error) {
+	conn, err := d.getConnection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	result, err := conn.ExecContext(ctx, unconfirmCrossposts)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// ForgetCrossposts drops the cross-posts of the article that are not being sent, so that the article
+// is queued again for its destinations when it is delivered next time.
+func (d *DestinationPostgresStorage) ForgetCrossposts(ctx context.Context, articleId int64) error {
+	conn, err := d.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err := conn.ExecContext(ctx, forgetCrossposts, articleId); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *DestinationPostgresStorage) selectDestinations(ctx context.Context, query string, args ...any) ([]model.Destination, error) {
+	conn, err := d.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var destinations []dbDestination
+	if err := conn.SelectContext(ctx, &destinations, query, args...); err != nil {
+		return nil, err
+	}
+
+	return lo.Map(destinations, func(destination dbDestination, _ int) model.Destination {
+		return model.Destination(destination)
+	}), nil
+}
+
+func (d *DestinationPostgresStorage) getConnection(ctx context.Context) (*sqlx.Conn, error) {
+	conn, err := d.db.Connx(ctx)
+	if err != nil {
+		log.Printf("[ERROR] Failed to get connection to database: %v", err)
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+type dbDestination struct {
+	ID        int64     `db:"id"`
+	TopicID   int64     `db:"topic_id"`
+	Type      string    `db:"type"`
+	URL       string    `db:"url"`
+	Token     string    `db:"token"`
+	Room      string    `db:"room"`
+	Account   string    `db:"account"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type dbCrosspost struct {
+	DestinationID int64        `db:"destination_id"`
+	ArticleID     int64        `db:"article_id"`
+	Summary       string       `db:"summary"`
+	State         string       `db:"state"`
+	Attempts      int          `db:"attempts"`
+	NextAttemptAt sql.NullTime `db:"next_attempt_at"`
+	LastError     string       `db:"last_error"`
+	PostedAt      sql.NullTime `db:"posted_at"`
+}
+
+func (c dbCrosspost) toModel() model.Crosspost {
+	return model.Crosspost{
+		DestinationID: c.DestinationID,
+		ArticleID:     c.ArticleID,
+		Summary:       c.Summary,
+		State:         c.State,
+		Attempts:      c.Attempts,
+		NextAttemptAt: c.NextAttemptAt.Time,
+		LastError:     c.LastError,
+		PostedAt:      c.PostedAt.Time,
+	}
+}
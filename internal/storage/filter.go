@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+	"log"
+	"strings"
+	"tg-bot/internal/model"
+	"tg-bot/internal/utils"
+	"time"
+)
+
+const (
+	selectAllFilters string = "SELECT * FROM filters ORDER BY keyword"
+	saveFilter       string = "INSERT INTO filters (keyword) VALUES ($1) RETURNING id"
+	updateFilter     string = "UPDATE filters SET keyword = $2 WHERE id = $1"
+	deleteFilter     string = "DELETE FROM filters WHERE id = $1"
+)
+
+type FilterPostgresStorage struct {
+	db *sqlx.DB
+}
+
+func NewFilterStorage(db *sqlx.DB) *FilterPostgresStorage {
+	return &FilterPostgresStorage{db: db}
+}
+
+func (f *FilterPostgresStorage) Filters(ctx context.Context) ([]model.Filter, error) {
+	conn, err := f.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var filters []dbFilter
+	if err := conn.SelectContext(ctx, &filters, selectAllFilters); err != nil {
+		return nil, err
+	}
+
+	return lo.Map(filters, func(filter dbFilter, _ int) model.Filter { return model.Filter(filter) }), nil
+}
+
+// Keywords returns the keywords of all filters.
+func (f *FilterPostgresStorage) Keywords(ctx context.Context) ([]string, error) {
+	filters, err := f.Filters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Map(filters, func(filter model.Filter, _ int) string { return filter.Keyword }), nil
+}
+
+// Save stores the keyword in lower case, the way the fetcher matches it.
+func (f *FilterPostgresStorage) Save(ctx context.Context, filter model.Filter) (int64, error) {
+	conn, err := f.getConnection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var id int64
+	if err := conn.GetContext(ctx, &id, saveFilter, strings.ToLower(strings.TrimSpace(filter.Keyword))); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Update replaces the keyword of the filter, returning sql.ErrNoRows if there is no filter with its ID.
+func (f *FilterPostgresStorage) Update(ctx context.Context, filter model.Filter) error {
+	conn, err := f.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	result, err := conn.ExecContext(ctx, updateFilter, filter.ID, strings.ToLower(strings.TrimSpace(filter.Keyword)))
+	if err != nil {
+		return err
+	}
+
+	return utils.RequireAffected(result)
+}
+
+func (f *FilterPostgresStorage) Delete(ctx context.Context, id int64) error {
+	conn, err := f.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err = conn.ExecContext(ctx, deleteFilter, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (f *FilterPostgresStorage) getConnection(ctx context.Context) (*sqlx.Conn, error) {
+	conn, err := f.db.Connx(ctx)
+	if err != nil {
+		log.Printf("[ERROR] Failed to get connection to database: %v", err)
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+type dbFilter struct {
+	ID        int64     `db:"id"`
+	Keyword   string    `db:"keyword"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+create table Channels
+(
+    id         bigint primary key generated by default as identity,
+    chat_id    bigint       not null unique,
+    name       varchar(255) not null,
+    created_at timestamp    not null default now()
+);
+
+create table Topic_Channels
+(
+    topic_id   bigint references Topics (id) on delete cascade   not null,
+    channel_id bigint references Channels (id) on delete cascade not null,
+    primary key (topic_id, channel_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists Topic_Channels;
+drop table if exists Channels;
+-- +goose StatementEnd
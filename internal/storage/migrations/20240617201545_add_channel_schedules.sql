@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table Channels
+    add column timezone           varchar(64)  not null default '',
+    add column schedule           varchar(255) not null default '',
+    add column quiet_hours        varchar(32)  not null default '',
+    add column max_posts_per_hour int          not null default 0;
+
+create table Article_Posts
+(
+    article_id bigint references Articles (id) on delete cascade not null,
+    channel_id bigint references Channels (id) on delete cascade not null,
+    posted_at  timestamp                                         not null default now(),
+    primary key (article_id, channel_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists Article_Posts;
+
+alter table Channels
+    drop column if exists timezone,
+    drop column if exists schedule,
+    drop column if exists quiet_hours,
+    drop column if exists max_posts_per_hour;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table Sources
+    add column priority double precision not null default 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table Sources
+    drop column if exists priority;
+-- +goose StatementEnd
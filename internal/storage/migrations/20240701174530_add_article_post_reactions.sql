@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table Article_Posts
+    drop constraint article_posts_pkey,
+    add column id         bigint primary key generated by default as identity,
+    alter column channel_id drop not null,
+    add column chat_id    bigint not null default 0,
+    add column message_id bigint not null default 0,
+    add column likes      int    not null default 0,
+    add column dislikes   int    not null default 0,
+    add constraint article_posts_article_channel_key unique (article_id, channel_id);
+
+update Article_Posts p
+set chat_id = c.chat_id
+from Channels c
+where c.id = p.channel_id;
+
+create index article_posts_chat_message_idx on Article_Posts (chat_id, message_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists article_posts_chat_message_idx;
+
+delete
+from Article_Posts
+where channel_id is null;
+
+alter table Article_Posts
+    drop constraint article_posts_article_channel_key,
+    drop column if exists id,
+    drop column if exists chat_id,
+    drop column if exists message_id,
+    drop column if exists likes,
+    drop column if exists dislikes,
+    alter column channel_id set not null,
+    add primary key (article_id, channel_id);
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table Channels
+    add column digest_mode    varchar(16) not null default '',
+    add column digest_time    varchar(8)  not null default '09:00',
+    add column digest_weekday int         not null default 1,
+    add column digest_size    int         not null default 10,
+    add column last_digest_at timestamp;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table Channels
+    drop column if exists digest_mode,
+    drop column if exists digest_time,
+    drop column if exists digest_weekday,
+    drop column if exists digest_size,
+    drop column if exists last_digest_at;
+-- +goose StatementEnd
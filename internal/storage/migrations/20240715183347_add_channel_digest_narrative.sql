@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table Channels
+    add column digest_narrative boolean not null default false;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table Channels
+    drop column if exists digest_narrative;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table Articles
+    add column status            varchar(32) not null default 'new',
+    add column generated_summary text        not null default '',
+    add column review_message_id bigint      not null default 0;
+
+create index articles_status_idx on Articles (status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists articles_status_idx;
+
+alter table Articles
+    drop column if exists status,
+    drop column if exists generated_summary,
+    drop column if exists review_message_id;
+-- +goose StatementEnd
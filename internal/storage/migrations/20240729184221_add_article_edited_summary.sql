@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table Articles
+    add column edited_summary text not null default '';
+
+create index articles_review_message_idx on Articles (review_message_id) where review_message_id <> 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists articles_review_message_idx;
+
+alter table Articles
+    drop column if exists edited_summary;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table Articles
+    add column summary_model  varchar(64) not null default '',
+    add column summary_prompt text        not null default '';
+
+alter table Article_Posts
+    add column summary text not null default '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table Article_Posts
+    drop column if exists summary;
+
+alter table Articles
+    drop column if exists summary_model,
+    drop column if exists summary_prompt;
+-- +goose StatementEnd
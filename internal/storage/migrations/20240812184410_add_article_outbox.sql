@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table Articles
+    add column publish_state varchar(16) not null default 'queued',
+    add column publish_error text        not null default '';
+
+update Articles
+set publish_state = 'posted'
+where posted_at is not null;
+
+create index articles_publish_state_idx on Articles (publish_state);
+
+alter table Article_Posts
+    add column state varchar(16) not null default 'posted';
+
+create unique index article_posts_default_channel_key on Article_Posts (article_id) where channel_id is null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists article_posts_default_channel_key;
+
+alter table Article_Posts
+    drop column if exists state;
+
+drop index if exists articles_publish_state_idx;
+
+alter table Articles
+    drop column if exists publish_state,
+    drop column if exists publish_error;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table Articles
+    add column publish_attempts int       not null default 0,
+    add column next_attempt_at  timestamp null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table Articles
+    drop column if exists publish_attempts,
+    drop column if exists next_attempt_at;
+-- +goose StatementEnd
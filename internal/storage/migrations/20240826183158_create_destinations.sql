@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+create table Destinations
+(
+    id         bigint primary key generated by default as identity,
+    topic_id   bigint references Topics (id) on delete cascade not null,
+    type       varchar(32)                                     not null,
+    url        text                                            not null,
+    token      text                                            not null default '',
+    room       varchar(255)                                    not null default '',
+    created_at timestamp                                       not null default now()
+);
+
+create index destinations_topic_id_idx on Destinations (topic_id);
+
+create table Destination_Posts
+(
+    destination_id bigint references Destinations (id) on delete cascade not null,
+    article_id     bigint references Articles (id) on delete cascade     not null,
+    posted_at      timestamp                                             not null default now(),
+    primary key (destination_id, article_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists Destination_Posts;
+drop table if exists Destinations;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table Destinations
+    add column account varchar(255) not null default '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table Destinations
+    drop column if exists account;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+create table Filters
+(
+    id         bigint primary key generated by default as identity,
+    keyword    varchar(255) not null unique,
+    created_at timestamp    not null default now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists Filters;
+-- +goose StatementEnd
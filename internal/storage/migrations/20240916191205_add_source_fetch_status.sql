@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table Sources
+    add column last_fetched_at timestamp,
+    add column last_error      text not null default '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table Sources
+    drop column if exists last_fetched_at,
+    drop column if exists last_error;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table Topics
+    add column posted_retention_days   int not null default 0,
+    add column unposted_retention_days int not null default 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table Topics
+    drop column if exists posted_retention_days,
+    drop column if exists unposted_retention_days;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table Destination_Posts
+    add column state           varchar(16) not null default 'posted',
+    add column summary         text        not null default '',
+    add column attempts        int         not null default 0,
+    add column next_attempt_at timestamp   null,
+    add column last_error      text        not null default '',
+    alter column posted_at drop not null,
+    alter column posted_at drop default;
+
+create index destination_posts_state_idx on Destination_Posts (state) where state <> 'posted';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists destination_posts_state_idx;
+
+delete from Destination_Posts where posted_at is null;
+
+alter table Destination_Posts
+    alter column posted_at set default now(),
+    alter column posted_at set not null,
+    drop column if exists state,
+    drop column if exists summary,
+    drop column if exists attempts,
+    drop column if exists next_attempt_at,
+    drop column if exists last_error;
+-- +goose StatementEnd
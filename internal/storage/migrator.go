@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+	"path"
+	"tg-bot/internal/storage/migrations"
+	"time"
+)
+
+// MigrationStatus tells whether a migration is applied and when. AppliedAt is zero for pending ones.
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt time.Time
+}
+
+// Migrator applies the embedded goose migrations. Applied versions are kept in goose's version table,
+// so the goose CLI keeps working on the same database.
+type Migrator struct {
+	// provider changes the schema holding a Postgres advisory lock, so replicas starting at the same
+	// time wait for each other; reader only reads the versions and never waits for the lock.
+	provider *goose.Provider
+	reader   *goose.Provider
+}
+
+func NewMigrator(db *sqlx.DB) (*Migrator, error) {
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, db.DB, migrations.FS, goose.WithSessionLocker(locker))
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := goose.NewProvider(goose.DialectPostgres, db.DB, migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{provider: provider, reader: reader}, nil
+}
+
+// Up applies all pending migrations in order and returns the names of those applied, also when a
+// later one failed.
+func (m *Migrator) Up(ctx context.Context) ([]string, error) {
+	results, err := m.provider.Up(ctx)
+
+	var partial *goose.PartialError
+	if errors.As(err, &partial) {
+		results = partial.Applied
+	}
+
+	names := make([]string, 0, len(results))
+	for _, result := range results {
+		names = append(names, path.Base(result.Source.Path))
+	}
+
+	return names, err
+}
+
+// Down rolls back the latest applied migration and returns its name, empty if none was applied.
+func (m *Migrator) Down(ctx context.Context) (string, error) {
+	result, err := m.provider.Down(ctx)
+	if errors.Is(err, goose.ErrNoNextVersion) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return path.Base(result.Source.Path), nil
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	statuses, err := m.reader.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]MigrationStatus, 0, len(statuses))
+	for _, status := range statuses {
+		result = append(result, MigrationStatus{
+			Version:   status.Source.Version,
+			Name:      path.Base(status.Source.Path),
+			AppliedAt: status.AppliedAt,
+		})
+	}
+
+	return result, nil
+}
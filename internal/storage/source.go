@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"database/sql"
 	"github.com/jmoiron/sqlx"
 	"github.com/samber/lo"
 	"log"
@@ -13,11 +14,19 @@ import (
 const (
 	selectAllSources string = "SELECT * from sources"
 	findSourceById   string = "SELECT * from sources where id = $1"
-	saveSource       string = "INSERT INTO sources (name, feed_url, topic_id, type) VALUES ($1, $2, $3, $4) RETURNING id"
+	saveSource       string = "INSERT INTO sources (name, feed_url, topic_id, type, priority) VALUES ($1, $2, $3, $4, $5) RETURNING id"
 	deleteSource     string = "DELETE FROM sources WHERE id = $1"
 	sourcesByTopicId string = "SELECT * FROM sources where topic_id = $1"
+	setPriority      string = "UPDATE sources SET priority = $2 WHERE id = $1"
+	updateSource     string = "UPDATE sources SET name = $2, feed_url = $3, topic_id = $4, type = $5, priority = $6 WHERE id = $1"
+	setFetchResult   string = "UPDATE sources SET last_fetched_at = $2::timestamp, last_error = $3 WHERE id = $1"
+	adjustPriority   string = "UPDATE sources SET priority = LEAST(GREATEST(priority + $2, $3), $4) " +
+		"WHERE id = (SELECT source_id FROM articles WHERE id = $1)"
 )
 
+// defaultPriority is the priority of a source saved without one, the same as the column default.
+const defaultPriority = 1
+
 type SourcePostgresStorage struct {
 	db *sqlx.DB
 }
@@ -39,7 +48,7 @@ func (s *SourcePostgresStorage) Sources(ctx context.Context) ([]model.Source, er
 	}
 
 	return lo.Map(sources, func(source dbSource, _ int) model.Source {
-		return model.Source(source)
+		return source.toModel()
 	}), nil
 }
 
@@ -55,7 +64,7 @@ func (s *SourcePostgresStorage) SourceById(ctx context.Context, id int64) (*mode
 		return nil, err
 	}
 
-	return (*model.Source)(&source), nil
+	return lo.ToPtr(source.toModel()), nil
 }
 
 func (s *SourcePostgresStorage) SourcesByTopicId(ctx context.Context, topicId int64) ([]model.Source, error) {
@@ -71,10 +80,11 @@ func (s *SourcePostgresStorage) SourcesByTopicId(ctx context.Context, topicId in
 	}
 
 	return lo.Map(sources, func(source dbSource, _ int) model.Source {
-		return model.Source(source)
+		return source.toModel()
 	}), nil
 }
 
+// Save stores a new source, with the default priority if it has none.
 func (s *SourcePostgresStorage) Save(ctx context.Context, source model.Source) (int64, error) {
 	conn, err := s.getConnection(ctx)
 	if err != nil {
@@ -84,8 +94,12 @@ func (s *SourcePostgresStorage) Save(ctx context.Context, source model.Source) (
 
 	var id int64
 
+	if source.Priority == 0 {
+		source.Priority = defaultPriority
+	}
+
 	row := conn.QueryRowxContext(ctx, saveSource,
-		source.Name, source.FeedURL, source.TopicID, source.Type)
+		source.Name, source.FeedURL, source.TopicID, source.Type, source.Priority)
 
 	if err := row.Err(); err != nil {
 		return 0, err
@@ -112,6 +126,81 @@ func (s *SourcePostgresStorage) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+// Update overwrites the source, returning sql.ErrNoRows if there is no source with its ID.
+func (s *SourcePostgresStorage) Update(ctx context.Context, source model.Source) error {
+	conn, err := s.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	result, err := conn.ExecContext(ctx, updateSource,
+		source.ID,
+		source.Name,
+		source.FeedURL,
+		source.TopicID,
+		source.Type,
+		source.Priority,
+	)
+	if err != nil {
+		return err
+	}
+
+	return utils.RequireAffected(result)
+}
+
+// SetPriority sets the priority of the source, returning sql.ErrNoRows if there is no source with the ID.
+func (s *SourcePostgresStorage) SetPriority(ctx context.Context, id int64, priority float64) error {
+	conn, err := s.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	result, err := conn.ExecContext(ctx, setPriority, id, priority)
+	if err != nil {
+		return err
+	}
+
+	return utils.RequireAffected(result)
+}
+
+// SetFetchResult records when the source was last fetched and why the fetch failed, if it did.
+func (s *SourcePostgresStorage) SetFetchResult(ctx context.Context, id int64, fetchedAt time.Time, fetchErr string) error {
+	conn, err := s.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err = conn.ExecContext(ctx, setFetchResult, id, fetchedAt.UTC().Format(time.RFC3339), fetchErr); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AdjustArticleSourcePriority shifts the priority of the article's source by delta, keeping it within [minPriority, maxPriority].
+func (s *SourcePostgresStorage) AdjustArticleSourcePriority(
+	ctx context.Context,
+	articleId int64,
+	delta float64,
+	minPriority float64,
+	maxPriority float64,
+) error {
+	conn, err := s.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err = conn.ExecContext(ctx, adjustPriority, articleId, delta, minPriority, maxPriority); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (s *SourcePostgresStorage) getConnection(ctx context.Context) (*sqlx.Conn, error) {
 	conn, err := s.db.Connx(ctx)
 	if err != nil {
@@ -123,10 +212,27 @@ func (s *SourcePostgresStorage) getConnection(ctx context.Context) (*sqlx.Conn,
 }
 
 type dbSource struct {
-	ID        int64     `db:"id"`
-	Name      string    `db:"name"`
-	FeedURL   string    `db:"feed_url"`
-	TopicID   int64     `db:"topic_id"`
-	Type      string    `db:"type"`
-	CreatedAt time.Time `db:"created_at"`
+	ID            int64        `db:"id"`
+	Name          string       `db:"name"`
+	FeedURL       string       `db:"feed_url"`
+	TopicID       int64        `db:"topic_id"`
+	Type          string       `db:"type"`
+	Priority      float64      `db:"priority"`
+	LastFetchedAt sql.NullTime `db:"last_fetched_at"`
+	LastError     string       `db:"last_error"`
+	CreatedAt     time.Time    `db:"created_at"`
+}
+
+func (s dbSource) toModel() model.Source {
+	return model.Source{
+		ID:            s.ID,
+		Name:          s.Name,
+		FeedURL:       s.FeedURL,
+		TopicID:       s.TopicID,
+		Type:          s.Type,
+		Priority:      s.Priority,
+		LastFetchedAt: s.LastFetchedAt.Time,
+		LastError:     s.LastError,
+		CreatedAt:     s.CreatedAt,
+	}
 }
@@ -11,8 +11,13 @@ import (
 )
 
 const (
-	selectAll = "SELECT * FROM topics"
-	saveTopic = "INSERT INTO topics (name, description) VALUES ($1, $2) RETURNING id"
+	selectAll   = "SELECT * FROM topics"
+	saveTopic   = "INSERT INTO topics (name, description) VALUES ($1, $2) RETURNING id"
+	findTopic   = "SELECT * FROM topics WHERE id = $1"
+	updateTopic = "UPDATE topics SET name = $2, description = $3, posted_retention_days = $4, " +
+		"unposted_retention_days = $5 WHERE id = $1"
+	setRetention = "UPDATE topics SET posted_retention_days = $2, unposted_retention_days = $3 WHERE id = $1"
+	deleteTopic  = "DELETE FROM topics WHERE id = $1"
 )
 
 type TopicPostgresStorage struct {
@@ -38,6 +43,21 @@ func (t *TopicPostgresStorage) Topics(ctx context.Context) ([]model.Topic, error
 	return lo.Map(topics, func(topic dbTopic, _ int) model.Topic { return model.Topic(topic) }), nil
 }
 
+func (t *TopicPostgresStorage) TopicById(ctx context.Context, id int64) (*model.Topic, error) {
+	conn, err := t.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	var topic dbTopic
+	if err := conn.GetContext(ctx, &topic, findTopic, id); err != nil {
+		return nil, err
+	}
+
+	return lo.ToPtr(model.Topic(topic)), nil
+}
+
 func (t *TopicPostgresStorage) Save(ctx context.Context, topic model.Topic) (int64, error) {
 	conn, err := t.getConnection(ctx)
 	if err != nil {
@@ -61,6 +81,54 @@ func (t *TopicPostgresStorage) Save(ctx context.Context, topic model.Topic) (int
 	return id, nil
 }
 
+// Update overwrites the topic, returning sql.ErrNoRows if there is no topic with its ID.
+func (t *TopicPostgresStorage) Update(ctx context.Context, topic model.Topic) error {
+	conn, err := t.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	result, err := conn.ExecContext(ctx, updateTopic,
+		topic.ID, topic.Name, topic.Description, topic.PostedRetentionDays, topic.UnpostedRetentionDays)
+	if err != nil {
+		return err
+	}
+
+	return utils.RequireAffected(result)
+}
+
+// SetRetention overrides the retention of the topic's articles, zero days keep the configured ones.
+func (t *TopicPostgresStorage) SetRetention(ctx context.Context, id int64, postedDays int, unpostedDays int) error {
+	conn, err := t.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	result, err := conn.ExecContext(ctx, setRetention, id, postedDays, unpostedDays)
+	if err != nil {
+		return err
+	}
+
+	return utils.RequireAffected(result)
+}
+
+// Delete removes the topic together with its sources and their articles.
+func (t *TopicPostgresStorage) Delete(ctx context.Context, id int64) error {
+	conn, err := t.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer utils.HandleCloseDbConnection(conn)
+
+	if _, err := conn.ExecContext(ctx, deleteTopic, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (t *TopicPostgresStorage) getConnection(ctx context.Context) (*sqlx.Conn, error) {
 	conn, err := t.db.Connx(ctx)
 	if err != nil {
@@ -72,8 +140,10 @@ func (t *TopicPostgresStorage) getConnection(ctx context.Context) (*sqlx.Conn, e
 }
 
 type dbTopic struct {
-	ID          int64     `db:"id"`
-	Name        string    `db:"name"`
-	Description string    `db:"description"`
-	CreatedAt   time.Time `db:"created_at"`
+	ID                    int64     `db:"id"`
+	Name                  string    `db:"name"`
+	Description           string    `db:"description"`
+	PostedRetentionDays   int       `db:"posted_retention_days"`
+	UnpostedRetentionDays int       `db:"unposted_retention_days"`
+	CreatedAt             time.Time `db:"created_at"`
 }
@@ -6,6 +6,8 @@ import (
 	"log"
 	"sync"
 	"tg-bot/internal/config"
+	"tg-bot/internal/metrics"
+	"time"
 
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/ollama"
@@ -46,7 +48,10 @@ func (l *LocalLLM) Request(ctx context.Context, text string, prompt string) (str
 
 	query := fmt.Sprintf("%s%s", prompt, text)
 
+	started := time.Now()
+
 	completion, err := llms.GenerateFromSinglePrompt(ctx, l.llm, query)
+	metrics.LLMRequestDuration.WithLabelValues(llmModel).Observe(time.Since(started).Seconds())
 	if err != nil {
 		log.Printf("[ERROR] failed to generate summary: %v", err)
 		return "", err
@@ -54,3 +59,7 @@ func (l *LocalLLM) Request(ctx context.Context, text string, prompt string) (str
 
 	return completion, nil
 }
+
+func (l *LocalLLM) Model() string {
+	return llmModel
+}
@@ -7,6 +7,8 @@ import (
 	"log"
 	"strings"
 	"sync"
+	"tg-bot/internal/metrics"
+	"time"
 )
 
 const (
@@ -54,11 +56,17 @@ func (s *OpenAIClient) Request(ctx context.Context, text string, prompt string)
 		TopP:        openAiTopP,
 	}
 
+	started := time.Now()
+
 	resp, err := s.client.CreateChatCompletion(ctx, request)
+	metrics.LLMRequestDuration.WithLabelValues(aiModel).Observe(time.Since(started).Seconds())
 	if err != nil {
 		return "", err
 	}
 
+	metrics.LLMTokens.WithLabelValues(aiModel, "prompt").Add(float64(resp.Usage.PromptTokens))
+	metrics.LLMTokens.WithLabelValues(aiModel, "completion").Add(float64(resp.Usage.CompletionTokens))
+
 	rawSummary := strings.TrimSpace(resp.Choices[0].Message.Content)
 	return cleanSummary(rawSummary), nil
 
@@ -72,3 +80,7 @@ func cleanSummary(rawSummary string) string {
 	sentences := strings.Split(rawSummary, ".")
 	return strings.Join(sentences[:len(sentences)-1], ".") + "."
 }
+
+func (s *OpenAIClient) Model() string {
+	return aiModel
+}
@@ -0,0 +1,155 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// RunFunc runs a component until the context is done.
+type RunFunc func(ctx context.Context) error
+
+type fatalError struct {
+	err error
+}
+
+func (e fatalError) Error() string { return e.err.Error() }
+
+func (e fatalError) Unwrap() error { return e.err }
+
+// Fatal marks an error that restarting can't fix. It stops all components.
+func Fatal(err error) error {
+	return fatalError{err: err}
+}
+
+type component struct {
+	name string
+	run  RunFunc
+}
+
+// Supervisor runs components like an errgroup, except that a component failing with an ordinary error
+// is restarted after a growing delay instead of stopping the others. A fatal error or the end of the
+// context shuts all components down, waiting for them up to the drain timeout.
+type Supervisor struct {
+	components   []component
+	restartDelay time.Duration
+	maxDelay     time.Duration
+	drainTimeout time.Duration
+}
+
+func New(restartDelay time.Duration, maxDelay time.Duration, drainTimeout time.Duration) *Supervisor {
+	return &Supervisor{
+		restartDelay: restartDelay,
+		maxDelay:     maxDelay,
+		drainTimeout: drainTimeout,
+	}
+}
+
+func (s *Supervisor) Add(name string, run RunFunc) {
+	s.components = append(s.components, component{name: name, run: run})
+}
+
+// Run starts all components and blocks until they have stopped. It returns the fatal error that stopped
+// them, if any, or an error if they did not stop within the drain timeout.
+func (s *Supervisor) Run(parent context.Context) error {
+	ctx, cancel := context.WithCancelCause(parent)
+	defer cancel(nil)
+
+	var wg sync.WaitGroup
+
+	for _, c := range s.components {
+		wg.Add(1)
+
+		go func(c component) {
+			defer wg.Done()
+
+			if err := s.supervise(ctx, c); err != nil {
+				cancel(err)
+			}
+		}(c)
+	}
+
+	<-ctx.Done()
+
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+
+	// Components only cancel the context with a fatal error; otherwise the shutdown was requested.
+	var err error
+	if parent.Err() == nil {
+		err = context.Cause(ctx)
+	}
+
+	timer := time.NewTimer(s.drainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-stopped:
+		return err
+	case <-timer.C:
+		return errors.Join(err, fmt.Errorf("components did not stop within %s", s.drainTimeout))
+	}
+}
+
+// supervise runs the component until the context is done, restarting it after failures. It returns
+// the error only when it is fatal.
+func (s *Supervisor) supervise(ctx context.Context, c component) error {
+	delay := s.restartDelay
+
+	for {
+		started := time.Now()
+
+		err := runSafely(ctx, c.run)
+		if ctx.Err() != nil {
+			log.Printf("%s stopped", c.name)
+			return nil
+		}
+
+		var fatal fatalError
+		if errors.As(err, &fatal) {
+			log.Printf("[ERROR] %s failed fatally: %v", c.name, err)
+			return fmt.Errorf("%s: %w", c.name, err)
+		}
+
+		if err == nil {
+			log.Printf("%s finished", c.name)
+			return nil
+		}
+
+		// A component that ran for a while before failing starts over with the shortest delay.
+		if time.Since(started) > s.maxDelay {
+			delay = s.restartDelay
+		}
+
+		log.Printf("[ERROR] %s failed, restarting in %s: %v", c.name, delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			log.Printf("%s stopped", c.name)
+			return nil
+		}
+
+		delay = min(2*delay, s.maxDelay)
+	}
+}
+
+// runSafely turns a panic of the component into an error, so that it is restarted like any failure.
+func runSafely(ctx context.Context, run RunFunc) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v\n%s", p, debug.Stack())
+		}
+	}()
+
+	return run(ctx)
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"database/sql"
 	"github.com/jmoiron/sqlx"
 	"log"
 )
@@ -11,3 +12,17 @@ func HandleCloseDbConnection(conn *sqlx.Conn) {
 		log.Printf("[ERROR] Failed to close connection to database: %v", err)
 	}
 }
+
+// RequireAffected returns sql.ErrNoRows if the statement changed no rows.
+func RequireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}